@@ -0,0 +1,221 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/protection"
+)
+
+const (
+	AuditMissing          = "missing"
+	AuditInvalidSignature = "invalid_signature"
+	// AuditNotAuditable marks nonces fx core no longer stores, their confirms can not be checked
+	AuditNotAuditable = "not_auditable"
+)
+
+// AuditFinding is an oracle set or batch the audited oracle did not confirm correctly.
+type AuditFinding struct {
+	Kind   string
+	Token  string
+	Nonce  uint64
+	Status string
+	Detail string
+}
+
+// SignatureAuditor walks the oracle sets and batches stored by fx core and checks the confirms of one oracle.
+type SignatureAuditor struct {
+	*FxTronBridge
+	bridgerAddr string
+	tronAddr    string
+}
+
+func NewSignatureAuditor(fxBridge *FxTronBridge, bridgerAddr string) (*SignatureAuditor, error) {
+	oracle, err := fxBridge.Querier.GetOracleByBridgerAddr(bridgerAddr, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureAuditor{
+		FxTronBridge: fxBridge,
+		bridgerAddr:  bridgerAddr,
+		tronAddr:     oracle.ExternalAddress,
+	}, nil
+}
+
+// gravityId reads the gravity id at the start of each audit, it can be changed by governance.
+func (a *SignatureAuditor) gravityId() (string, error) {
+	params, err := a.Querier.Params(fxtronbridge.Tron)
+	if err != nil {
+		return "", err
+	}
+	return params.GravityId, nil
+}
+
+// AuditOracleSets checks every stored oracle set from fromNonce the oracle is a member of.
+func (a *SignatureAuditor) AuditOracleSets(fromNonce uint64) ([]AuditFinding, error) {
+	gravityId, err := a.gravityId()
+	if err != nil {
+		return nil, err
+	}
+	oracleSets, err := a.Querier.LastOracleSetRequests(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	var toNonce uint64
+	for _, oracleSet := range oracleSets {
+		if oracleSet.Nonce > toNonce {
+			toNonce = oracleSet.Nonce
+		}
+	}
+
+	findings := make([]AuditFinding, 0)
+	// prunedFrom is the first nonce of the current run of oracle sets pruned by fx core, 0 outside a run
+	var prunedFrom uint64
+	for nonce := fromNonce; nonce <= toNonce; nonce++ {
+		oracleSet, err := a.Querier.OracleSetRequest(nonce, fxtronbridge.Tron)
+		if err != nil {
+			return nil, err
+		}
+		if oracleSet == nil {
+			if prunedFrom == 0 {
+				prunedFrom = nonce
+			}
+			continue
+		}
+		if prunedFrom > 0 {
+			findings = append(findings, prunedFinding(protection.KindOracleSet, prunedFrom, nonce-1))
+			prunedFrom = 0
+		}
+		if !a.isMember(oracleSet) {
+			continue
+		}
+		confirms, err := a.Querier.OracleSetConfirmsByNonce(nonce, fxtronbridge.Tron)
+		if err != nil {
+			return nil, err
+		}
+		var signature string
+		for _, confirm := range confirms {
+			if confirm.BridgerAddress == a.bridgerAddr {
+				signature = confirm.Signature
+				break
+			}
+		}
+		hash, err := contract.EncodeOracleSetConfirmHash(gravityId, *oracleSet)
+		if err != nil {
+			return nil, err
+		}
+		if finding := a.checkSignature(protection.KindOracleSet, "", nonce, hash, signature); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	if prunedFrom > 0 {
+		findings = append(findings, prunedFinding(protection.KindOracleSet, prunedFrom, toNonce))
+	}
+	return findings, nil
+}
+
+// AuditBatches checks every batch from fromNonce still stored by fx core, batches executed on tron
+// are removed from fx core together with their confirms and can not be audited. The nonces from fromNonce
+// below the lowest stored batch are reported as not auditable, batch nonces are shared by every token.
+func (a *SignatureAuditor) AuditBatches(fromNonce uint64) ([]AuditFinding, error) {
+	gravityId, err := a.gravityId()
+	if err != nil {
+		return nil, err
+	}
+	txBatches, err := a.Querier.OutgoingTxBatches(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(txBatches, func(i, j int) bool {
+		if txBatches[i].TokenContract != txBatches[j].TokenContract {
+			return txBatches[i].TokenContract < txBatches[j].TokenContract
+		}
+		return txBatches[i].BatchNonce < txBatches[j].BatchNonce
+	})
+
+	findings := make([]AuditFinding, 0)
+	if len(txBatches) > 0 {
+		lowestNonce := txBatches[0].BatchNonce
+		for _, txBatch := range txBatches {
+			if txBatch.BatchNonce < lowestNonce {
+				lowestNonce = txBatch.BatchNonce
+			}
+		}
+		if fromNonce < lowestNonce {
+			findings = append(findings, prunedFinding(protection.KindBatch, fromNonce, lowestNonce-1))
+		}
+	}
+	for _, txBatch := range txBatches {
+		if txBatch.BatchNonce < fromNonce {
+			continue
+		}
+		confirms, err := a.Querier.BatchConfirms(txBatch.BatchNonce, txBatch.TokenContract, fxtronbridge.Tron)
+		if err != nil {
+			return nil, err
+		}
+		var signature string
+		for _, confirm := range confirms {
+			if confirm.BridgerAddress == a.bridgerAddr {
+				signature = confirm.Signature
+				break
+			}
+		}
+		hash, err := contract.EncodeConfirmBatchHash(gravityId, *txBatch)
+		if err != nil {
+			return nil, err
+		}
+		if finding := a.checkSignature(protection.KindBatch, txBatch.TokenContract, txBatch.BatchNonce, hash, signature); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	return findings, nil
+}
+
+// prunedFinding reports the nonces fromNonce to toNonce as no longer stored by fx core.
+func prunedFinding(kind string, fromNonce, toNonce uint64) AuditFinding {
+	return AuditFinding{Kind: kind, Nonce: fromNonce, Status: AuditNotAuditable,
+		Detail: fmt.Sprintf("nonces %d to %d not auditable (pruned)", fromNonce, toNonce)}
+}
+
+func (a *SignatureAuditor) isMember(oracleSet *crosschaintypes.OracleSet) bool {
+	for _, member := range oracleSet.Members {
+		if member.ExternalAddress == a.tronAddr {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *SignatureAuditor) checkSignature(kind, token string, nonce uint64, hash []byte, signature string) *AuditFinding {
+	if len(signature) <= 0 {
+		return &AuditFinding{Kind: kind, Token: token, Nonce: nonce, Status: AuditMissing, Detail: "no confirm from " + a.bridgerAddr}
+	}
+	signer, err := recoverTronAddress(hash, signature)
+	if err != nil {
+		return &AuditFinding{Kind: kind, Token: token, Nonce: nonce, Status: AuditInvalidSignature, Detail: err.Error()}
+	}
+	if signer != a.tronAddr {
+		return &AuditFinding{Kind: kind, Token: token, Nonce: nonce, Status: AuditInvalidSignature, Detail: "signed by " + signer}
+	}
+	return nil
+}
+
+// recoverTronAddress returns the tron address of the key that signed hash, signature is hex encoded.
+func recoverTronAddress(hash []byte, signature string) (string, error) {
+	sign, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature hex: %w", err)
+	}
+	pubKey, err := crypto.SigToPub(hash, sign)
+	if err != nil {
+		return "", fmt.Errorf("recover signature: %w", err)
+	}
+	return address.PubkeyToAddress(*pubKey).String(), nil
+}
@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/protection"
+)
+
+func TestSignatureAuditor(t *testing.T) {
+	fxBridge, _, _, chain := newMemFxTronBridgeWithState(t)
+	bridger := fxBridge.GetBridgerAddr().String()
+	newMember := func(power uint64) crosschaintypes.BridgeValidator {
+		return crosschaintypes.BridgeValidator{Power: power, ExternalAddress: fxBridge.GetTronAddr().String()}
+	}
+	sign := func(hash []byte) string {
+		signature, err := crypto.Sign(hash, fxBridge.TronPrivKey)
+		require.NoError(t, err)
+		return hex.EncodeToString(signature)
+	}
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	chain.oracleSets = []*crosschaintypes.OracleSet{
+		{Nonce: 1, Members: crosschaintypes.BridgeValidators{newMember(fxtronbridge.ThresholdVotePower)}},
+		{Nonce: 2, Members: crosschaintypes.BridgeValidators{newMember(fxtronbridge.ThresholdVotePower + 1)}},
+		{Nonce: 3, Members: crosschaintypes.BridgeValidators{newMember(fxtronbridge.ThresholdVotePower + 2)}},
+		// the bridger is not a member and is not expected to confirm
+		{Nonce: 4, Members: crosschaintypes.BridgeValidators{{Power: fxtronbridge.ThresholdVotePower, ExternalAddress: testBridgeAddr}}},
+	}
+	hash1, err := contract.EncodeOracleSetConfirmHash("tron", *chain.oracleSets[0])
+	require.NoError(t, err)
+	hash3, err := contract.EncodeOracleSetConfirmHash("tron", *chain.oracleSets[2])
+	require.NoError(t, err)
+	otherSignature, err := crypto.Sign(hash3, otherKey)
+	require.NoError(t, err)
+	chain.setConfirms = []*crosschaintypes.MsgOracleSetConfirm{
+		{Nonce: 1, BridgerAddress: bridger, Signature: sign(hash1)},
+		{Nonce: 3, BridgerAddress: bridger, Signature: hex.EncodeToString(otherSignature)},
+	}
+
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(2, 1000), newTestTxBatch(5, 1000)}
+	batchHash, err := contract.EncodeConfirmBatchHash("tron", *chain.batches[1])
+	require.NoError(t, err)
+	chain.batchConfirms = []*crosschaintypes.MsgConfirmBatch{
+		{Nonce: 5, TokenContract: testTokenAddr, BridgerAddress: bridger, Signature: sign(batchHash)},
+	}
+
+	auditor, err := NewSignatureAuditor(fxBridge, bridger)
+	require.NoError(t, err)
+	findings, err := auditor.AuditOracleSets(1)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	require.Equal(t, AuditFinding{Kind: protection.KindOracleSet, Nonce: 2, Status: AuditMissing, Detail: "no confirm from " + bridger}, findings[0])
+	require.Equal(t, uint64(3), findings[1].Nonce)
+	require.Equal(t, AuditInvalidSignature, findings[1].Status)
+
+	findings, err = auditor.AuditOracleSets(3)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+
+	// the batch nonce 1 below the lowest stored batch was pruned with its confirms
+	findings, err = auditor.AuditBatches(1)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	require.Equal(t, AuditFinding{Kind: protection.KindBatch, Nonce: 1, Status: AuditNotAuditable, Detail: "nonces 1 to 1 not auditable (pruned)"}, findings[0])
+	require.Equal(t, testTokenAddr, findings[1].Token)
+	require.Equal(t, uint64(2), findings[1].Nonce)
+	require.Equal(t, AuditMissing, findings[1].Status)
+
+	// only the stored batches from fromNonce are audited
+	findings, err = auditor.AuditBatches(3)
+	require.NoError(t, err)
+	require.Empty(t, findings)
+
+	// the next audit hashes with the gravity id changed by governance
+	chain.params.GravityId = "tron-2"
+	findings, err = auditor.AuditBatches(2)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	require.Equal(t, uint64(5), findings[1].Nonce)
+	require.Equal(t, AuditInvalidSignature, findings[1].Status)
+
+	// the oracle sets pruned by fx core are reported instead of skipped
+	chain.oracleSets = chain.oracleSets[2:]
+	findings, err = auditor.AuditOracleSets(1)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	require.Equal(t, AuditFinding{Kind: protection.KindOracleSet, Nonce: 1, Status: AuditNotAuditable, Detail: "nonces 1 to 2 not auditable (pruned)"}, findings[0])
+	require.Equal(t, uint64(3), findings[1].Nonce)
+	require.Equal(t, AuditInvalidSignature, findings[1].Status)
+}
@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"context"
+	"math/big"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+// EventSource is the external chain the oracle reads the bridge contract events from.
+type EventSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	QueryBlockEvent(contractAddress string, blockNumber uint64) ([]contract.IEvent, error)
+	QueryOracleSetUpdatedEvent(contractAddress string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error)
+}
+
+// BridgeState reads the bridge contract state on the external chain.
+type BridgeState interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	GetBridgeTokenList(contractAddress string) ([]contract.FxBridgeToken, error)
+	GetTokenStatus(contractAddress, tokenAddress string) (bool, bool, bool, error)
+	BalanceOf(tokenAddress, owner string) (*big.Int, error)
+	LastBatchNonce(contractAddress string, erc20Address string) (uint64, error)
+	StateLastOracleSetNonce(contractAddress string) (uint64, error)
+	StateLastOracleSetCheckpoint(contractAddress string) ([32]byte, error)
+	StatePowerThreshold(contractAddress string) (uint64, error)
+	MakeCheckpoint(contractAddress string, oracleSet crosschaintypes.OracleSet, fxBridgeId string) ([32]byte, error)
+}
+
+// CrosschainQuerier reads the crosschain module state of fx core.
+type CrosschainQuerier interface {
+	Params(chainName string) (*crosschaintypes.Params, error)
+	CurrentOracleSet(chainName string) (*crosschaintypes.OracleSet, error)
+	OracleSetRequest(nonce uint64, chainName string) (*crosschaintypes.OracleSet, error)
+	LastOracleSetRequests(chainName string) ([]*crosschaintypes.OracleSet, error)
+	OracleSetConfirmsByNonce(nonce uint64, chainName string) ([]*crosschaintypes.MsgOracleSetConfirm, error)
+	BatchRequestByNonce(nonce uint64, tokenContract, chainName string) (*crosschaintypes.OutgoingTxBatch, error)
+	OutgoingTxBatches(chainName string) ([]*crosschaintypes.OutgoingTxBatch, error)
+	BatchConfirms(nonce uint64, tokenContract, chainName string) ([]*crosschaintypes.MsgConfirmBatch, error)
+	TokenToDenom(token, chainName string) (*crosschaintypes.QueryTokenToDenomResponse, error)
+	SupplyOf(denom string) (sdk.Coin, error)
+	GetOracleByBridgerAddr(bridgerAddress string, chainName string) (*crosschaintypes.Oracle, error)
+	Oracles(chainName string) ([]crosschaintypes.Oracle, error)
+	LastEventNonceByAddr(bridgerAddress string, chainName string) (uint64, error)
+	LastEventBlockHeightByAddr(bridgerAddress string, chainName string) (uint64, error)
+	LastPendingOracleSetRequestByAddr(bridgerAddress string, chainName string) ([]*crosschaintypes.OracleSet, error)
+	QueryBalance(address string, denom string) (sdk.Coin, error)
+	GetLatestBlock() (*tmproto.Block, error)
+}
+
+// NewBlockSubscriber pushes the height of every new fx core block.
+type NewBlockSubscriber interface {
+	SubscribeNewBlock(ctx context.Context) (<-chan int64, error)
+}
+
+// TxBroadcaster signs and broadcasts the bridger messages to fx core.
+type TxBroadcaster interface {
+	BuildTx(privKey cryptotypes.PrivKey, msgs []sdk.Msg) (*tx.TxRaw, error)
+	BroadcastTx(txRaw *tx.TxRaw, mode ...tx.BroadcastMode) (*sdk.TxResponse, error)
+}
@@ -0,0 +1,439 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+type memEventSource struct {
+	blockNumber uint64
+	events      map[uint64][]contract.IEvent
+}
+
+func (m *memEventSource) BlockNumber(context.Context) (uint64, error) {
+	return m.blockNumber, nil
+}
+
+func (m *memEventSource) QueryBlockEvent(_ string, blockNumber uint64) ([]contract.IEvent, error) {
+	return m.events[blockNumber], nil
+}
+
+func (m *memEventSource) QueryOracleSetUpdatedEvent(_ string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error) {
+	events := make([]*contract.FxBridgeTronOracleSetUpdatedEvent, 0)
+	for _, event := range m.events[blockNumber] {
+		if oracleSetUpdated, ok := event.(*contract.FxBridgeTronOracleSetUpdatedEvent); ok {
+			events = append(events, oracleSetUpdated)
+		}
+	}
+	return events, nil
+}
+
+type memBridgeState struct {
+	blockNumber        uint64
+	tokens             []contract.FxBridgeToken
+	inactiveTokens     map[string]bool
+	lastBatchNonce     map[string]uint64
+	lastOracleSetNonce uint64
+	checkpoint         [32]byte
+	balances           map[string]*big.Int
+	originated         map[string]bool
+	powerThreshold     uint64
+}
+
+func (m *memBridgeState) BlockNumber(context.Context) (uint64, error) {
+	return m.blockNumber, nil
+}
+
+func (m *memBridgeState) GetBridgeTokenList(string) ([]contract.FxBridgeToken, error) {
+	return m.tokens, nil
+}
+
+func (m *memBridgeState) GetTokenStatus(_, tokenAddress string) (bool, bool, bool, error) {
+	for _, token := range m.tokens {
+		if contract.AddressToString(token.Addr) == tokenAddress {
+			return m.originated[tokenAddress], !m.inactiveTokens[tokenAddress], true, nil
+		}
+	}
+	return false, false, false, nil
+}
+
+func (m *memBridgeState) BalanceOf(tokenAddress, _ string) (*big.Int, error) {
+	if balance, ok := m.balances[tokenAddress]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (m *memBridgeState) LastBatchNonce(_ string, erc20Address string) (uint64, error) {
+	return m.lastBatchNonce[erc20Address], nil
+}
+
+func (m *memBridgeState) StateLastOracleSetNonce(string) (uint64, error) {
+	return m.lastOracleSetNonce, nil
+}
+
+func (m *memBridgeState) StateLastOracleSetCheckpoint(string) ([32]byte, error) {
+	return m.checkpoint, nil
+}
+
+func (m *memBridgeState) StatePowerThreshold(string) (uint64, error) {
+	return m.powerThreshold, nil
+}
+
+// MakeCheckpoint hashes the oracle set like the contract, the exact encoding does not matter for the fake.
+func (m *memBridgeState) MakeCheckpoint(_ string, oracleSet crosschaintypes.OracleSet, fxBridgeId string) ([32]byte, error) {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s/%v", fxBridgeId, oracleSet))), nil
+}
+
+type memChain struct {
+	params         crosschaintypes.Params
+	oracle         *crosschaintypes.Oracle
+	oracles        []crosschaintypes.Oracle
+	lastEventNonce uint64
+	eventNonces    map[string]uint64
+	currentSet     *crosschaintypes.OracleSet
+	batches        []*crosschaintypes.OutgoingTxBatch
+	batchConfirms  []*crosschaintypes.MsgConfirmBatch
+	setConfirms    []*crosschaintypes.MsgOracleSetConfirm
+	denoms         map[string]string
+	supply         sdk.Coins
+	oracleSets     []*crosschaintypes.OracleSet
+	pendingSets    []*crosschaintypes.OracleSet
+	balance        sdk.Coin
+	blockHeight    int64
+
+	built    [][]sdk.Msg
+	sent     [][]sdk.Msg
+	sendCode uint32
+}
+
+func (m *memChain) Params(string) (*crosschaintypes.Params, error) {
+	return &m.params, nil
+}
+
+func (m *memChain) CurrentOracleSet(string) (*crosschaintypes.OracleSet, error) {
+	return m.currentSet, nil
+}
+
+// OracleSetRequest returns the stored oracle set, falling back to the pending sets when none is stored.
+func (m *memChain) OracleSetRequest(nonce uint64, _ string) (*crosschaintypes.OracleSet, error) {
+	for _, oracleSet := range append(append([]*crosschaintypes.OracleSet{}, m.oracleSets...), m.pendingSets...) {
+		if oracleSet.Nonce == nonce {
+			return oracleSet, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memChain) LastOracleSetRequests(string) ([]*crosschaintypes.OracleSet, error) {
+	return m.oracleSets, nil
+}
+
+func (m *memChain) OracleSetConfirmsByNonce(nonce uint64, _ string) ([]*crosschaintypes.MsgOracleSetConfirm, error) {
+	confirms := make([]*crosschaintypes.MsgOracleSetConfirm, 0)
+	for _, confirm := range m.setConfirms {
+		if confirm.Nonce == nonce {
+			confirms = append(confirms, confirm)
+		}
+	}
+	return confirms, nil
+}
+
+func (m *memChain) BatchRequestByNonce(nonce uint64, tokenContract, _ string) (*crosschaintypes.OutgoingTxBatch, error) {
+	for _, batch := range m.batches {
+		if batch.BatchNonce == nonce && batch.TokenContract == tokenContract {
+			return batch, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memChain) OutgoingTxBatches(string) ([]*crosschaintypes.OutgoingTxBatch, error) {
+	return m.batches, nil
+}
+
+func (m *memChain) BatchConfirms(nonce uint64, tokenContract, _ string) ([]*crosschaintypes.MsgConfirmBatch, error) {
+	confirms := make([]*crosschaintypes.MsgConfirmBatch, 0)
+	for _, confirm := range m.batchConfirms {
+		if confirm.Nonce == nonce && confirm.TokenContract == tokenContract {
+			confirms = append(confirms, confirm)
+		}
+	}
+	return confirms, nil
+}
+
+func (m *memChain) TokenToDenom(token, _ string) (*crosschaintypes.QueryTokenToDenomResponse, error) {
+	denom, ok := m.denoms[token]
+	if !ok {
+		return nil, fmt.Errorf("token %s not found", token)
+	}
+	return &crosschaintypes.QueryTokenToDenomResponse{Denom: denom}, nil
+}
+
+func (m *memChain) SupplyOf(denom string) (sdk.Coin, error) {
+	return sdk.NewCoin(denom, m.supply.AmountOf(denom)), nil
+}
+
+func (m *memChain) GetOracleByBridgerAddr(bridgerAddress string, _ string) (*crosschaintypes.Oracle, error) {
+	if m.oracle == nil || m.oracle.BridgerAddress != bridgerAddress {
+		return nil, fmt.Errorf("oracle not found bridger: %s", bridgerAddress)
+	}
+	return m.oracle, nil
+}
+
+func (m *memChain) Oracles(string) ([]crosschaintypes.Oracle, error) {
+	return m.oracles, nil
+}
+
+func (m *memChain) LastEventNonceByAddr(bridgerAddress string, _ string) (uint64, error) {
+	if nonce, ok := m.eventNonces[bridgerAddress]; ok {
+		return nonce, nil
+	}
+	return m.lastEventNonce, nil
+}
+
+func (m *memChain) LastEventBlockHeightByAddr(string, string) (uint64, error) {
+	return 0, nil
+}
+
+func (m *memChain) LastPendingOracleSetRequestByAddr(string, string) ([]*crosschaintypes.OracleSet, error) {
+	return m.pendingSets, nil
+}
+
+func (m *memChain) QueryBalance(string, string) (sdk.Coin, error) {
+	return m.balance, nil
+}
+
+func (m *memChain) GetLatestBlock() (*tmproto.Block, error) {
+	return &tmproto.Block{Header: tmproto.Header{Height: m.blockHeight}}, nil
+}
+
+// BuildTx keeps the messages aside, the returned tx only carries their index.
+func (m *memChain) BuildTx(_ cryptotypes.PrivKey, msgs []sdk.Msg) (*tx.TxRaw, error) {
+	m.built = append(m.built, msgs)
+	return &tx.TxRaw{BodyBytes: []byte(strconv.Itoa(len(m.built) - 1))}, nil
+}
+
+func (m *memChain) BroadcastTx(txRaw *tx.TxRaw, _ ...tx.BroadcastMode) (*sdk.TxResponse, error) {
+	index, err := strconv.Atoi(string(txRaw.BodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	m.blockHeight++
+	if m.sendCode != 0 {
+		return &sdk.TxResponse{Height: m.blockHeight, Code: m.sendCode, Codespace: "crosschain"}, nil
+	}
+	m.sent = append(m.sent, m.built[index])
+	for _, msg := range m.built[index] {
+		if confirm, ok := msg.(*crosschaintypes.MsgConfirmBatch); ok {
+			m.batchConfirms = append(m.batchConfirms, confirm)
+		}
+	}
+	return &sdk.TxResponse{Height: m.blockHeight}, nil
+}
+
+func newMemFxTronBridge(t *testing.T) (*FxTronBridge, *memEventSource, *memChain) {
+	fxBridge, eventSource, _, chain := newMemFxTronBridgeWithState(t)
+	return fxBridge, eventSource, chain
+}
+
+func newMemFxTronBridgeWithState(t *testing.T) (*FxTronBridge, *memEventSource, *memBridgeState, *memChain) {
+	t.Setenv("HOME", t.TempDir())
+	tronPrivKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	eventSource := &memEventSource{events: make(map[uint64][]contract.IEvent)}
+	bridgeState := &memBridgeState{
+		inactiveTokens: make(map[string]bool),
+		lastBatchNonce: make(map[string]uint64),
+		balances:       make(map[string]*big.Int),
+		originated:     make(map[string]bool),
+	}
+	chain := &memChain{params: crosschaintypes.Params{GravityId: "tron"}, blockHeight: 1, denoms: make(map[string]string)}
+	fxBridge := NewFxTronBridge(testBridgeAddr, eventSource, bridgeState, chain, chain, secp256k1.GenPrivKey(), tronPrivKey)
+	chain.oracle = &crosschaintypes.Oracle{
+		BridgerAddress:  fxBridge.GetBridgerAddr().String(),
+		ExternalAddress: fxBridge.GetTronAddr().String(),
+		Online:          true,
+	}
+	return fxBridge, eventSource, bridgeState, chain
+}
+
+func TestOracleClaimsEventsInNonceOrder(t *testing.T) {
+	fxBridge, eventSource, chain := newMemFxTronBridge(t)
+	chain.lastEventNonce = 3
+	eventSource.blockNumber = 100
+	eventSource.events[60] = []contract.IEvent{
+		&contract.FxBridgeTronSendToFxEvent{EventNonce: big.NewInt(5), Amount: big.NewInt(1)},
+		&contract.FxBridgeTronAddBridgeTokenEvent{EventNonce: big.NewInt(4), TokenContract: ethcommon.HexToAddress("0x1")},
+		&contract.FxBridgeTronSendToFxEvent{EventNonce: big.NewInt(3), Amount: big.NewInt(1)},
+	}
+
+	oracle := &Oracle{FxTronBridge: fxBridge, startBlockNumber: 50}
+	require.NoError(t, oracle.bridgeEvent())
+
+	require.Len(t, chain.sent, 1)
+	require.Len(t, chain.sent[0], 2)
+	require.Equal(t, uint64(4), chain.sent[0][0].(*crosschaintypes.MsgBridgeTokenClaim).EventNonce)
+	require.Equal(t, uint64(5), chain.sent[0][1].(*crosschaintypes.MsgSendToFxClaim).EventNonce)
+	require.Equal(t, uint64(100-25), oracle.startBlockNumber)
+}
+
+func TestOracleKeepsStartBlockWhenBroadcastFails(t *testing.T) {
+	fxBridge, eventSource, chain := newMemFxTronBridge(t)
+	chain.sendCode = 4
+	eventSource.blockNumber = 100
+	eventSource.events[60] = []contract.IEvent{
+		&contract.FxBridgeTronSendToFxEvent{EventNonce: big.NewInt(1), Amount: big.NewInt(1)},
+	}
+
+	oracle := &Oracle{FxTronBridge: fxBridge, startBlockNumber: 50}
+	require.Error(t, oracle.bridgeEvent())
+	require.Len(t, chain.sent, 0)
+	require.Equal(t, uint64(50), oracle.startBlockNumber)
+}
+
+func TestOracleInactiveAlert(t *testing.T) {
+	fxBridge, eventSource, chain := newMemFxTronBridge(t)
+	eventSource.blockNumber = 100
+	oracle := &Oracle{FxTronBridge: fxBridge, startBlockNumber: 100}
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+
+	// an active oracle does not alert
+	require.NoError(t, singer.confirm())
+	require.NoError(t, oracle.bridgeEvent())
+	require.False(t, notifier.IsFiring(notifier.AlertOracleInactive))
+
+	// the oracle is jailed on fx core
+	chain.oracle.Online = false
+	require.NoError(t, oracle.bridgeEvent())
+	require.True(t, notifier.IsFiring(notifier.AlertOracleInactive))
+	notifier.Resolve(notifier.AlertOracleInactive)
+	require.NoError(t, singer.confirm())
+	require.True(t, notifier.IsFiring(notifier.AlertOracleInactive))
+
+	chain.oracle.Online = true
+	require.NoError(t, singer.confirm())
+	require.False(t, notifier.IsFiring(notifier.AlertOracleInactive))
+}
+
+func TestSingerRejectsMismatchedTronKey(t *testing.T) {
+	fxBridge, _, chain := newMemFxTronBridge(t)
+	chain.oracle.ExternalAddress = testBridgeAddr
+	chain.pendingSets = []*crosschaintypes.OracleSet{{Nonce: 1}}
+
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.Error(t, singer.confirm())
+	require.Len(t, chain.built, 0)
+}
+
+func TestSingerRefusesConflictingOracleSetDigest(t *testing.T) {
+	fxBridge, _, chain := newMemFxTronBridge(t)
+	chain.pendingSets = []*crosschaintypes.OracleSet{
+		{Nonce: 1, Members: crosschaintypes.BridgeValidators{{Power: fxtronbridge.ThresholdVotePower, ExternalAddress: fxBridge.GetTronAddr().String()}}},
+	}
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 1)
+
+	// a forked node returns another oracle set with the same nonce
+	chain.pendingSets = []*crosschaintypes.OracleSet{
+		{Nonce: 1, Members: crosschaintypes.BridgeValidators{{Power: fxtronbridge.ThresholdVotePower + 1, ExternalAddress: fxBridge.GetTronAddr().String()}}},
+	}
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.built, 1)
+	require.Equal(t, 1, singer.signErrCount)
+}
+
+func TestSingerConfirmsAllPendingBatches(t *testing.T) {
+	const otherTokenAddr = "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f"
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	bridgeState.blockNumber = 500
+	bridgeState.tokens = []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr), newTestBridgeToken(t, otherTokenAddr)}
+
+	newer, older, confirmed := newTestTxBatch(5, 1000), newTestTxBatch(2, 1000), newTestTxBatch(4, 1000)
+	newer.Block, older.Block, confirmed.Block = 20, 10, 5
+	older.TokenContract = otherTokenAddr
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newer, older, confirmed}
+	chain.batchConfirms = []*crosschaintypes.MsgConfirmBatch{
+		{Nonce: 4, TokenContract: testTokenAddr, BridgerAddress: fxBridge.GetBridgerAddr().String()},
+	}
+
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 1)
+	require.Len(t, chain.sent[0], 2)
+	require.Equal(t, otherTokenAddr, chain.sent[0][0].(*crosschaintypes.MsgConfirmBatch).TokenContract)
+	require.Equal(t, uint64(5), chain.sent[0][1].(*crosschaintypes.MsgConfirmBatch).Nonce)
+
+	// every batch is confirmed now
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.built, 1)
+}
+
+func TestSingerConfirmsBatchesAfterConflictingDigest(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	bridgeState.blockNumber = 500
+	bridgeState.tokens = []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr)}
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(2, 1000)}
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 1)
+
+	// a forked node returns another batch with the same nonce, it is sorted before the new batch
+	conflicting, next := newTestTxBatch(2, 1000), newTestTxBatch(3, 1000)
+	conflicting.FeeReceive, next.Block = testBridgeAddr, 10
+	chain.batches, chain.batchConfirms = []*crosschaintypes.OutgoingTxBatch{next, conflicting}, nil
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 2)
+	require.Len(t, chain.sent[1], 1)
+	require.Equal(t, uint64(3), chain.sent[1][0].(*crosschaintypes.MsgConfirmBatch).Nonce)
+	require.Equal(t, 1, singer.signErrCount)
+}
+
+func TestSingerSendsClosestDeadlineFirst(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	chain.params.SignedWindow = 100
+	bridgeState.blockNumber = 500
+	bridgeState.tokens = []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr)}
+	oracleSet := &crosschaintypes.OracleSet{Nonce: 1, Height: 30, Members: crosschaintypes.BridgeValidators{
+		{Power: fxtronbridge.ThresholdVotePower, ExternalAddress: fxBridge.GetTronAddr().String()},
+	}}
+	chain.pendingSets = []*crosschaintypes.OracleSet{oracleSet}
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(3, 1000)}
+
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 1)
+	require.Len(t, chain.sent[0], 2)
+	// the batch created at block 9 must be confirmed before block 109, the oracle set before block 130
+	require.IsType(t, &crosschaintypes.MsgConfirmBatch{}, chain.sent[0][0])
+	require.IsType(t, &crosschaintypes.MsgOracleSetConfirm{}, chain.sent[0][1])
+
+	chain.params.GravityId, chain.params.SignedWindow = "tron-2", 200
+	require.NoError(t, singer.refreshParams())
+	require.Equal(t, "tron-2", singer.gravityId)
+	require.Equal(t, uint64(200), singer.signedWindow)
+}
@@ -0,0 +1,164 @@
+package bridge
+
+import (
+	"fmt"
+
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/protection"
+)
+
+// ConfirmCheck is the result of recovering the signer of one confirm.
+type ConfirmCheck struct {
+	BridgerAddress  string
+	ExternalAddress string
+	Signer          string
+	Member          bool
+	Power           uint64
+	Valid           bool
+	Reason          string
+}
+
+type confirmSignature struct {
+	bridgerAddress  string
+	externalAddress string
+	signature       string
+}
+
+// ConfirmReport predicts whether the confirms of a batch or oracle set are accepted by the contract,
+// it only counts the power of valid signatures from members of the contract oracle set.
+type ConfirmReport struct {
+	Kind           string
+	Token          string
+	Nonce          uint64
+	Checks         []ConfirmCheck
+	ValidPower     uint64
+	PowerThreshold uint64
+}
+
+// Sufficient is the check of the contract, which only accepts signatures with more power than the threshold.
+func (r *ConfirmReport) Sufficient() bool {
+	return r.ValidPower > r.PowerThreshold
+}
+
+// InvalidCount is the number of member confirms that make submitBatch or updateOracleSet revert when submitted,
+// confirms of oracles outside the contract oracle set are never submitted.
+func (r *ConfirmReport) InvalidCount() int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Member && !check.Valid {
+			count++
+		}
+	}
+	return count
+}
+
+// VerifyBatchConfirms checks the confirms of the batch stored by fx core for token and nonce.
+func (f *FxTronBridge) VerifyBatchConfirms(token string, nonce uint64) (*ConfirmReport, error) {
+	txBatch, err := f.Querier.BatchRequestByNonce(nonce, token, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	if txBatch == nil {
+		return nil, fmt.Errorf("fx core batch not found token: %s, nonce: %d", token, nonce)
+	}
+	params, err := f.Querier.Params(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := contract.EncodeConfirmBatchHash(params.GravityId, *txBatch)
+	if err != nil {
+		return nil, err
+	}
+	confirms, err := f.Querier.BatchConfirms(nonce, token, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]confirmSignature, 0, len(confirms))
+	for _, confirm := range confirms {
+		signatures = append(signatures, confirmSignature{bridgerAddress: confirm.BridgerAddress, externalAddress: confirm.ExternalAddress, signature: confirm.Signature})
+	}
+	return f.verifyConfirms(protection.KindBatch, token, nonce, hash, signatures)
+}
+
+// VerifyOracleSetConfirms checks the confirms of the oracle set stored by fx core for nonce.
+func (f *FxTronBridge) VerifyOracleSetConfirms(nonce uint64) (*ConfirmReport, error) {
+	oracleSet, err := f.Querier.OracleSetRequest(nonce, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	if oracleSet == nil {
+		return nil, fmt.Errorf("fx core oracle set request not found nonce: %d", nonce)
+	}
+	params, err := f.Querier.Params(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := contract.EncodeOracleSetConfirmHash(params.GravityId, *oracleSet)
+	if err != nil {
+		return nil, err
+	}
+	confirms, err := f.Querier.OracleSetConfirmsByNonce(nonce, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]confirmSignature, 0, len(confirms))
+	for _, confirm := range confirms {
+		signatures = append(signatures, confirmSignature{bridgerAddress: confirm.BridgerAddress, externalAddress: confirm.ExternalAddress, signature: confirm.Signature})
+	}
+	return f.verifyConfirms(protection.KindOracleSet, "", nonce, hash, signatures)
+}
+
+// verifyConfirms recovers the signer of every confirm, submitBatch and updateOracleSet both verify
+// the signatures against the oracle set stored by the contract.
+func (f *FxTronBridge) verifyConfirms(kind, token string, nonce uint64, hash []byte, signatures []confirmSignature) (*ConfirmReport, error) {
+	contractNonce, err := f.BridgeState.StateLastOracleSetNonce(f.BridgeAddr)
+	if err != nil {
+		return nil, err
+	}
+	contractSet, err := f.Querier.OracleSetRequest(contractNonce, fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	if contractSet == nil {
+		return nil, fmt.Errorf("fx core oracle set request not found nonce: %d", contractNonce)
+	}
+	powerThreshold, err := f.BridgeState.StatePowerThreshold(f.BridgeAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConfirmReport{Kind: kind, Token: token, Nonce: nonce, Checks: make([]ConfirmCheck, 0, len(signatures)), PowerThreshold: powerThreshold}
+	powers := contractSetPowers(contractSet)
+	for _, signature := range signatures {
+		check := ConfirmCheck{BridgerAddress: signature.bridgerAddress, ExternalAddress: signature.externalAddress}
+		check.Power, check.Member = powers[signature.externalAddress]
+		signer, err := recoverTronAddress(hash, signature.signature)
+		switch {
+		case err != nil:
+			check.Reason = err.Error()
+		case signer != signature.externalAddress:
+			check.Signer = signer
+			check.Reason = "signer does not match external address"
+		case !check.Member:
+			check.Signer = signer
+			check.Reason = "not a member of the contract oracle set"
+		default:
+			check.Signer = signer
+			check.Valid = true
+			report.ValidPower += check.Power
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+func contractSetPowers(oracleSet *crosschaintypes.OracleSet) map[string]uint64 {
+	powers := make(map[string]uint64, len(oracleSet.Members))
+	for _, member := range oracleSet.Members {
+		powers[member.ExternalAddress] = member.Power
+	}
+	return powers
+}
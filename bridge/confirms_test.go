@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+func TestVerifyBatchConfirms(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	outsiderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tronAddr := fxBridge.GetTronAddr().String()
+	otherAddr := address.PubkeyToAddress(otherKey.PublicKey).String()
+	outsiderAddr := address.PubkeyToAddress(outsiderKey.PublicKey).String()
+
+	bridgeState.lastOracleSetNonce = 1
+	bridgeState.powerThreshold = 150
+	chain.oracleSets = []*crosschaintypes.OracleSet{{Nonce: 1, Members: crosschaintypes.BridgeValidators{
+		{Power: 100, ExternalAddress: tronAddr},
+		{Power: 100, ExternalAddress: otherAddr},
+	}}}
+	txBatch := newTestTxBatch(3, 1000)
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{txBatch}
+	hash, err := contract.EncodeConfirmBatchHash("tron", *txBatch)
+	require.NoError(t, err)
+	ourSignature, err := crypto.Sign(hash, fxBridge.TronPrivKey)
+	require.NoError(t, err)
+	outsiderSignature, err := crypto.Sign(hash, outsiderKey)
+	require.NoError(t, err)
+	chain.batchConfirms = []*crosschaintypes.MsgConfirmBatch{
+		{Nonce: 3, TokenContract: testTokenAddr, BridgerAddress: "a", ExternalAddress: tronAddr, Signature: hex.EncodeToString(ourSignature)},
+		// the other member confirms with a signature of the outsider key
+		{Nonce: 3, TokenContract: testTokenAddr, BridgerAddress: "b", ExternalAddress: otherAddr, Signature: hex.EncodeToString(outsiderSignature)},
+		{Nonce: 3, TokenContract: testTokenAddr, BridgerAddress: "c", ExternalAddress: outsiderAddr, Signature: hex.EncodeToString(outsiderSignature)},
+	}
+
+	report, err := fxBridge.VerifyBatchConfirms(testTokenAddr, 3)
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 3)
+	require.True(t, report.Checks[0].Valid)
+	require.False(t, report.Checks[1].Valid)
+	require.Equal(t, outsiderAddr, report.Checks[1].Signer)
+	require.False(t, report.Checks[2].Member)
+	require.Equal(t, 1, report.InvalidCount())
+	require.Equal(t, uint64(100), report.ValidPower)
+	require.False(t, report.Sufficient())
+
+	// the contract needs more power than the threshold
+	bridgeState.powerThreshold = 100
+	report, err = fxBridge.VerifyBatchConfirms(testTokenAddr, 3)
+	require.NoError(t, err)
+	require.False(t, report.Sufficient())
+	bridgeState.powerThreshold = 99
+	report, err = fxBridge.VerifyBatchConfirms(testTokenAddr, 3)
+	require.NoError(t, err)
+	require.True(t, report.Sufficient())
+
+	_, err = fxBridge.VerifyBatchConfirms(testTokenAddr, 4)
+	require.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+)
+
+// ClaimProgress is the power of the active oracles that have claimed an event on fx core, an oracle
+// claims events in nonce order so its last event nonce tells whether it claimed the event.
+type ClaimProgress struct {
+	EventNonce   uint64
+	ClaimedPower uint64
+	TotalPower   uint64
+	Claimed      []string
+	Pending      []string
+}
+
+// Attested approximates the fx core attestation, which is observed once the oracles that claimed the
+// event hold strictly more than AttestationPowerThreshold percent of the active oracle power.
+func (p *ClaimProgress) Attested() bool {
+	return p.TotalPower > 0 && p.ClaimedPower > p.TotalPower*fxtronbridge.AttestationPowerThreshold/100
+}
+
+// ClaimProgress reads the last event nonce of every active oracle, weighted by its delegation like fx core.
+func (f *FxTronBridge) ClaimProgress(eventNonce uint64) (*ClaimProgress, error) {
+	oracles, err := f.Querier.Oracles(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	progress := &ClaimProgress{EventNonce: eventNonce}
+	for _, oracle := range oracles {
+		if !oracle.Online {
+			continue
+		}
+		power := oraclePower(oracle)
+		progress.TotalPower += power
+		lastEventNonce, err := f.Querier.LastEventNonceByAddr(oracle.BridgerAddress, fxtronbridge.Tron)
+		if err != nil {
+			return nil, err
+		}
+		if lastEventNonce >= eventNonce {
+			progress.ClaimedPower += power
+			progress.Claimed = append(progress.Claimed, oracle.BridgerAddress)
+		} else {
+			progress.Pending = append(progress.Pending, oracle.BridgerAddress)
+		}
+	}
+	if progress.TotalPower <= 0 {
+		return nil, fmt.Errorf("fx core has no active oracle power")
+	}
+	return progress, nil
+}
+
+// oraclePower is the power fx core counts for the oracle votes, its delegation in power units.
+func oraclePower(oracle crosschaintypes.Oracle) uint64 {
+	if oracle.DelegateAmount.IsNil() {
+		return 0
+	}
+	return oracle.DelegateAmount.Quo(sdk.DefaultPowerReduction).Uint64()
+}
@@ -0,0 +1,43 @@
+package bridge
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimProgress(t *testing.T) {
+	fxBridge, _, _, chain := newMemFxTronBridgeWithState(t)
+	// the oracle set power is ignored, fx core weights the votes by the oracle delegation
+	chain.currentSet = &crosschaintypes.OracleSet{Nonce: 2, Members: crosschaintypes.BridgeValidators{
+		{ExternalAddress: "TTronOracle1", Power: 1},
+	}}
+	chain.oracles = []crosschaintypes.Oracle{
+		{BridgerAddress: "fx1bridger1", ExternalAddress: "TTronOracle1", Online: true, DelegateAmount: sdk.TokensFromConsensusPower(2000, sdk.DefaultPowerReduction)},
+		{BridgerAddress: "fx1bridger2", ExternalAddress: "TTronOracle2", Online: true, DelegateAmount: sdk.TokensFromConsensusPower(1000, sdk.DefaultPowerReduction)},
+		{BridgerAddress: "fx1bridger3", ExternalAddress: "TTronOracle3", Online: true, DelegateAmount: sdk.TokensFromConsensusPower(1000, sdk.DefaultPowerReduction)},
+		{BridgerAddress: "fx1bridger4", ExternalAddress: "TTronOracle4", Online: false, DelegateAmount: sdk.TokensFromConsensusPower(5000, sdk.DefaultPowerReduction)},
+	}
+	chain.eventNonces = map[string]uint64{"fx1bridger1": 10, "fx1bridger2": 9, "fx1bridger3": 12}
+
+	progress, err := fxBridge.ClaimProgress(10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3000), progress.ClaimedPower)
+	require.Equal(t, uint64(4000), progress.TotalPower)
+	require.Equal(t, []string{"fx1bridger1", "fx1bridger3"}, progress.Claimed)
+	require.Equal(t, []string{"fx1bridger2"}, progress.Pending)
+	require.True(t, progress.Attested())
+
+	progress, err = fxBridge.ClaimProgress(11)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), progress.ClaimedPower)
+	require.False(t, progress.Attested())
+
+	// exactly 66% of the power is not enough, fx core needs strictly more
+	progress = &ClaimProgress{ClaimedPower: 66, TotalPower: 100}
+	require.False(t, progress.Attested())
+	progress.ClaimedPower = 67
+	require.True(t, progress.Attested())
+}
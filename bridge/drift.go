@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+// DriftDetector compares the oracle set stored by the tron contract with the oracle sets of fx core.
+type DriftDetector struct {
+	*FxTronBridge
+}
+
+func NewDriftDetector(fxBridge *FxTronBridge) *DriftDetector {
+	return &DriftDetector{FxTronBridge: fxBridge}
+}
+
+func (d *DriftDetector) check() error {
+	// the gravity id is read on each check so a governance change does not raise a false checkpoint mismatch
+	params, err := d.Querier.Params(fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	contractNonce, err := d.BridgeState.StateLastOracleSetNonce(d.BridgeAddr)
+	if err != nil {
+		return err
+	}
+	contractSet, err := d.Querier.OracleSetRequest(contractNonce, fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	if contractSet == nil {
+		return fmt.Errorf("fx core oracle set request not found nonce: %d", contractNonce)
+	}
+
+	onChainCheckpoint, err := d.BridgeState.StateLastOracleSetCheckpoint(d.BridgeAddr)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := d.BridgeState.MakeCheckpoint(d.BridgeAddr, *contractSet, params.GravityId)
+	if err != nil {
+		return err
+	}
+	if checkpoint != onChainCheckpoint {
+		fxtronbridge.OracleSetCheckpointMatchProm.Set(0)
+		logger.Errorw("oracle set checkpoint mismatch", "oracle_set_nonce", contractNonce,
+			"checkpoint", hex.EncodeToString(checkpoint[:]), "contract_checkpoint", hex.EncodeToString(onChainCheckpoint[:]))
+		notifier.Fire(notifier.AlertCheckpointMismatch, "contract checkpoint %x differs from fx core oracle set nonce %d checkpoint %x",
+			onChainCheckpoint, contractNonce, checkpoint)
+	} else {
+		fxtronbridge.OracleSetCheckpointMatchProm.Set(1)
+		notifier.Resolve(notifier.AlertCheckpointMismatch)
+	}
+
+	currentSet, err := d.Querier.CurrentOracleSet(fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	powerThreshold, err := d.BridgeState.StatePowerThreshold(d.BridgeAddr)
+	if err != nil {
+		return err
+	}
+	powerDiff, reachablePower := oracleSetDrift(contractSet, currentSet)
+	fxtronbridge.OracleSetPowerDiffProm.Set(float64(powerDiff) / math.MaxUint32)
+	fxtronbridge.OracleSetReachablePowerProm.Set(float64(reachablePower))
+	fxtronbridge.OracleSetPowerThresholdProm.Set(float64(powerThreshold))
+	if currentSet.Nonce > contractNonce {
+		fxtronbridge.OracleSetNonceLagProm.Set(float64(currentSet.Nonce - contractNonce))
+	} else {
+		fxtronbridge.OracleSetNonceLagProm.Set(0)
+	}
+	logger.Debugw("oracle set drift", "oracle_set_nonce", contractNonce, "current_oracle_set_nonce", currentSet.Nonce,
+		"power_diff", powerDiff, "reachable_power", reachablePower, "power_threshold", powerThreshold)
+
+	// the contract only accepts signatures with more power than the threshold
+	if reachablePower <= powerThreshold {
+		notifier.Fire(notifier.AlertOracleSetUnreachable, "contract oracle set nonce %d can reach power %d with the active oracles, threshold %d",
+			contractNonce, reachablePower, powerThreshold)
+	} else {
+		notifier.Resolve(notifier.AlertOracleSetUnreachable)
+	}
+	return nil
+}
+
+// oracleSetDrift returns the power that moved between the contract set and the current set, and the power
+// of the contract set members that are still active oracles in the current set.
+func oracleSetDrift(contractSet, currentSet *crosschaintypes.OracleSet) (uint64, uint64) {
+	currentPowers := make(map[string]uint64, len(currentSet.Members))
+	for _, member := range currentSet.Members {
+		currentPowers[member.ExternalAddress] = member.Power
+	}
+	var diff, reachablePower uint64
+	for _, member := range contractSet.Members {
+		currentPower, ok := currentPowers[member.ExternalAddress]
+		if ok {
+			reachablePower += member.Power
+		}
+		if currentPower > member.Power {
+			diff += currentPower - member.Power
+		} else {
+			diff += member.Power - currentPower
+		}
+		delete(currentPowers, member.ExternalAddress)
+	}
+	for _, power := range currentPowers {
+		diff += power
+	}
+	return diff / 2, reachablePower
+}
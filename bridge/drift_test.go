@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"testing"
+
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+func TestOracleSetDrift(t *testing.T) {
+	contractSet := &crosschaintypes.OracleSet{Members: crosschaintypes.BridgeValidators{
+		{Power: 100, ExternalAddress: "a"},
+		{Power: 200, ExternalAddress: "b"},
+		{Power: 700, ExternalAddress: "c"},
+	}}
+	currentSet := &crosschaintypes.OracleSet{Members: crosschaintypes.BridgeValidators{
+		{Power: 150, ExternalAddress: "a"},
+		{Power: 150, ExternalAddress: "b"},
+		{Power: 700, ExternalAddress: "d"},
+	}}
+	powerDiff, reachablePower := oracleSetDrift(contractSet, currentSet)
+	require.Equal(t, uint64(750), powerDiff)
+	require.Equal(t, uint64(300), reachablePower)
+
+	powerDiff, reachablePower = oracleSetDrift(contractSet, contractSet)
+	require.Equal(t, uint64(0), powerDiff)
+	require.Equal(t, uint64(1000), reachablePower)
+}
+
+func TestDriftDetectorCheck(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	contractSet := &crosschaintypes.OracleSet{Nonce: 2, Members: crosschaintypes.BridgeValidators{
+		{Power: 100, ExternalAddress: "a"},
+		{Power: 200, ExternalAddress: "b"},
+	}}
+	chain.oracleSets = []*crosschaintypes.OracleSet{contractSet}
+	chain.currentSet = &crosschaintypes.OracleSet{Nonce: 3, Members: crosschaintypes.BridgeValidators{{Power: 300, ExternalAddress: "b"}}}
+	bridgeState.lastOracleSetNonce = 2
+	bridgeState.powerThreshold = 150
+	checkpoint, err := bridgeState.MakeCheckpoint(testBridgeAddr, *contractSet, "tron")
+	require.NoError(t, err)
+	bridgeState.checkpoint = checkpoint
+
+	driftDetector := NewDriftDetector(fxBridge)
+	require.NoError(t, driftDetector.check())
+	require.Equal(t, float64(1), testutil.ToFloat64(fxtronbridge.OracleSetCheckpointMatchProm))
+	require.False(t, notifier.IsFiring(notifier.AlertOracleSetUnreachable))
+
+	// the reachable power 200 must exceed the threshold
+	bridgeState.powerThreshold = 200
+	require.NoError(t, driftDetector.check())
+	require.True(t, notifier.IsFiring(notifier.AlertOracleSetUnreachable))
+	bridgeState.powerThreshold = 199
+	require.NoError(t, driftDetector.check())
+	require.False(t, notifier.IsFiring(notifier.AlertOracleSetUnreachable))
+
+	// the gravity id changed by governance is used by the next check
+	chain.params.GravityId = "tron-2"
+	require.NoError(t, driftDetector.check())
+	require.Equal(t, float64(0), testutil.ToFloat64(fxtronbridge.OracleSetCheckpointMatchProm))
+	checkpoint, err = bridgeState.MakeCheckpoint(testBridgeAddr, *contractSet, "tron-2")
+	require.NoError(t, err)
+	bridgeState.checkpoint = checkpoint
+	require.NoError(t, driftDetector.check())
+	require.Equal(t, float64(1), testutil.ToFloat64(fxtronbridge.OracleSetCheckpointMatchProm))
+
+	// the contract set is unknown to fx core
+	bridgeState.lastOracleSetNonce = 5
+	require.Error(t, driftDetector.check())
+}
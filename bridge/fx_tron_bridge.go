@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
@@ -14,37 +16,52 @@ import (
 	"github.com/functionx/fx-tron-bridge/client"
 	"github.com/functionx/fx-tron-bridge/fxchain"
 	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
 )
 
 type FxTronBridge struct {
-	TronClient       *client.TronClient
-	CrossChainClient *fxchain.CrossChainClient
-	BridgeAddr       string
-	OrcPrivKey       *secp256k1.PrivKey
-	TronPrivKey      *ecdsa.PrivateKey
+	EventSource EventSource
+	BridgeState BridgeState
+	Querier     CrosschainQuerier
+	Broadcaster TxBroadcaster
+	// BlockSubscriber triggers the singer on new fx core blocks, the latest block is polled when nil
+	BlockSubscriber NewBlockSubscriber
+	BridgeAddr      string
+	OrcPrivKey      *secp256k1.PrivKey
+	TronPrivKey     *ecdsa.PrivateKey
+
+	// sendMu keeps the oracle and the singer from building txs with the same account sequence
+	sendMu sync.Mutex
 }
 
-func NewFxTronBridge(bridgeAddr, tronGrpc, fxGrpc string, orcPrivKey *secp256k1.PrivKey, tronPrivateKey *ecdsa.PrivateKey) (*FxTronBridge, error) {
+func NewFxTronBridge(bridgeAddr string, eventSource EventSource, bridgeState BridgeState, querier CrosschainQuerier, broadcaster TxBroadcaster, orcPrivKey *secp256k1.PrivKey, tronPrivateKey *ecdsa.PrivateKey) *FxTronBridge {
+	return &FxTronBridge{
+		EventSource: eventSource,
+		BridgeState: bridgeState,
+		Querier:     querier,
+		Broadcaster: broadcaster,
+		BridgeAddr:  bridgeAddr,
+		OrcPrivKey:  orcPrivKey,
+		TronPrivKey: tronPrivateKey,
+	}
+}
+
+// NewGrpcFxTronBridge connects to the tron and fx core grpc nodes and uses them as the bridge chains.
+func NewGrpcFxTronBridge(bridgeAddr, tronGrpc, fxGrpc string, tronOptions, fxOptions fxtronbridge.GrpcOptions, orcPrivKey *secp256k1.PrivKey, tronPrivateKey *ecdsa.PrivateKey) (*FxTronBridge, error) {
 	logger.Infof("NewFxTronBridge, bridgeAddr: %s, tronGrpc: %s, fxGrpc: %s", bridgeAddr, tronGrpc, fxGrpc)
 
-	tronClient, err := client.NewTronGrpcClient(tronGrpc)
+	tronClient, err := client.NewTronGrpcClientWithOptions(tronGrpc, tronOptions)
 	if err != nil {
 		return nil, err
 	}
 
 	ctx := context.Background()
-	crossChainClient, err := fxchain.NewCrossChainClient(ctx, fxGrpc)
+	crossChainClient, err := fxchain.NewCrossChainClientWithOptions(ctx, fxGrpc, fxOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &FxTronBridge{
-		BridgeAddr:       bridgeAddr,
-		OrcPrivKey:       orcPrivKey,
-		TronPrivKey:      tronPrivateKey,
-		TronClient:       tronClient,
-		CrossChainClient: crossChainClient,
-	}, nil
+	return NewFxTronBridge(bridgeAddr, tronClient, tronClient, crossChainClient, crossChainClient, orcPrivKey, tronPrivateKey), nil
 }
 
 func (f *FxTronBridge) GetBridgerAddr() sdk.AccAddress {
@@ -55,13 +72,19 @@ func (f *FxTronBridge) GetTronAddr() address.Address {
 	return address.PubkeyToAddress(f.TronPrivKey.PublicKey)
 }
 
-func (f *FxTronBridge) setFxKeyBalanceMetrics(fees string) {
-	balance, err := f.CrossChainClient.QueryBalance(f.GetBridgerAddr().String(), fees)
+func (f *FxTronBridge) setFxKeyBalanceMetrics(fees string, feeBalanceWarn float64) {
+	balance, err := f.Querier.QueryBalance(f.GetBridgerAddr().String(), fees)
 	if err != nil {
 		logger.Errorf("query balance fail fees: %s, err: %s", fees, err.Error())
 		return
 	}
-	fxtronbridge.FxKeyBalanceProm.Set(float64(balance.Amount.Quo(sdk.NewInt(1e18)).Uint64()))
+	fxKeyBalance, _ := new(big.Float).Quo(new(big.Float).SetInt(balance.Amount.BigInt()), big.NewFloat(1e18)).Float64()
+	fxtronbridge.FxKeyBalanceProm.Set(fxKeyBalance)
+	if fxKeyBalance < feeBalanceWarn {
+		notifier.Fire(notifier.AlertLowFeeBalance, "bridger %s fee balance %.4f %s is below %.4f", f.GetBridgerAddr().String(), fxKeyBalance, fees, feeBalanceWarn)
+	} else {
+		notifier.Resolve(notifier.AlertLowFeeBalance)
+	}
 }
 
 func (f *FxTronBridge) WaitNewBlock() error {
@@ -71,14 +94,14 @@ func (f *FxTronBridge) WaitNewBlock() error {
 	var lastFxBlockNumber int64 = 0
 
 	for {
-		tronBlockNumber, err := f.TronClient.BlockNumber(context.Background())
+		tronBlockNumber, err := f.EventSource.BlockNumber(context.Background())
 		if err != nil {
 			return err
 		}
 		if lastTronBlockNumber <= 0 && tronBlockNumber > 0 {
 			lastTronBlockNumber = tronBlockNumber
 		}
-		fxBlock, err := f.CrossChainClient.GetLatestBlock()
+		fxBlock, err := f.Querier.GetLatestBlock()
 		if err != nil {
 			return err
 		}
@@ -116,20 +139,26 @@ func (f *FxTronBridge) BatchSendMsg(msgs []sdk.Msg, batchNumber int) error {
 }
 
 func (f *FxTronBridge) sendMsg(msgs []sdk.Msg) error {
-	txRaw, err := f.CrossChainClient.BuildTx(f.OrcPrivKey, msgs)
+	f.sendMu.Lock()
+	defer f.sendMu.Unlock()
+	txRaw, err := f.Broadcaster.BuildTx(f.OrcPrivKey, msgs)
 	if err != nil {
-		logger.Errorf("build tx fail messages len: %d, err: %s", len(msgs), err.Error())
+		logger.Errorw("build tx fail", "msg_len", len(msgs), "error", err)
+		fxtronbridge.BroadcastFailProm.WithLabelValues("build_tx").Inc()
 		return err
 	}
-	txResp, err := f.CrossChainClient.BroadcastTx(txRaw)
+	txResp, err := f.Broadcaster.BroadcastTx(txRaw)
 	if err != nil {
-		logger.Errorf("broadcast tx fail messages len: %d, err: %s", len(msgs), err.Error())
+		logger.Errorw("broadcast tx fail", "msg_len", len(msgs), "error", err)
+		fxtronbridge.BroadcastFailProm.WithLabelValues("broadcast_tx").Inc()
 		return err
 	}
 	if txResp.Code != 0 {
+		fxtronbridge.BroadcastFailProm.WithLabelValues(fmt.Sprintf("%s_%d", txResp.Codespace, txResp.Code)).Inc()
+		logger.Errorw("send msg fail", "height", txResp.Height, "tx_hash", txResp.TxHash, "code", txResp.Code, "raw_log", txResp.RawLog)
 		return fmt.Errorf("send msg fail Height: %d, Hash: %s, code: %d", txResp.Height, txResp.TxHash, txResp.Code)
 	} else {
-		logger.Infof("send msg success Height: %d, Hash: %s", txResp.Height, txResp.TxHash)
+		logger.Infow("send msg success", "height", txResp.Height, "tx_hash", txResp.TxHash, "msg_len", len(msgs))
 	}
 	return nil
 }
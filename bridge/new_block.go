@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"context"
+	"time"
+
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+)
+
+// newBlockHeights sends the height of every new fx core block. The heights come from subscriber when it is
+// set, the latest block is polled every pollInterval while there is no subscription or it stays silent.
+// A height the receiver has not taken yet is replaced by the newer one.
+func newBlockHeights(ctx context.Context, subscriber NewBlockSubscriber, querier CrosschainQuerier, pollInterval time.Duration) <-chan int64 {
+	heights := make(chan int64, 1)
+	go func() {
+		var lastHeight int64
+		send := func(height int64) {
+			if height <= lastHeight {
+				return
+			}
+			lastHeight = height
+			select {
+			case <-heights:
+			default:
+			}
+			heights <- height
+		}
+		var pushed <-chan int64
+		subscribe := func() {
+			if subscriber == nil {
+				return
+			}
+			var err error
+			if pushed, err = subscriber.SubscribeNewBlock(ctx); err != nil {
+				logger.Warnw("subscribe fx new block fail, polling the latest block", "error", err)
+			}
+		}
+		subscribe()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		received := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case height, ok := <-pushed:
+				if !ok {
+					pushed = nil
+					continue
+				}
+				received = true
+				send(height)
+			case <-ticker.C:
+				if received {
+					received = false
+					continue
+				}
+				if pushed == nil {
+					subscribe()
+				}
+				fxBlock, err := querier.GetLatestBlock()
+				if err != nil {
+					logger.Warnw("get fx latest block fail", "error", err)
+					continue
+				}
+				send(fxBlock.Header.Height)
+			}
+		}
+	}()
+	return heights
+}
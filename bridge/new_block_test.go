@@ -0,0 +1,85 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// heightQuerier only answers the latest block, its height can change while the heights are polled.
+type heightQuerier struct {
+	CrosschainQuerier
+	height int64
+}
+
+func (q *heightQuerier) GetLatestBlock() (*tmproto.Block, error) {
+	return &tmproto.Block{Header: tmproto.Header{Height: atomic.LoadInt64(&q.height)}}, nil
+}
+
+type chanSubscriber struct {
+	heights chan int64
+	err     error
+}
+
+func (s *chanSubscriber) SubscribeNewBlock(context.Context) (<-chan int64, error) {
+	return s.heights, s.err
+}
+
+func receiveHeight(t *testing.T, heights <-chan int64) int64 {
+	select {
+	case height := <-heights:
+		return height
+	case <-time.After(5 * time.Second):
+		t.Fatal("no new block height")
+		return 0
+	}
+}
+
+func TestNewBlockHeightsSubscribed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscriber := &chanSubscriber{heights: make(chan int64)}
+	heights := newBlockHeights(ctx, subscriber, &heightQuerier{height: 1}, time.Hour)
+
+	subscriber.heights <- 5
+	require.Equal(t, int64(5), receiveHeight(t, heights))
+	subscriber.heights <- 5
+	subscriber.heights <- 6
+	require.Equal(t, int64(6), receiveHeight(t, heights))
+
+	// a receiver that falls behind gets the latest height
+	subscriber.heights <- 7
+	subscriber.heights <- 8
+	subscriber.heights <- 9
+	require.Eventually(t, func() bool { return len(heights) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, int64(9), receiveHeight(t, heights))
+}
+
+func TestNewBlockHeightsPolled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	querier := &heightQuerier{height: 10}
+	heights := newBlockHeights(ctx, &chanSubscriber{err: errors.New("connection refused")}, querier, 10*time.Millisecond)
+	require.Equal(t, int64(10), receiveHeight(t, heights))
+
+	atomic.StoreInt64(&querier.height, 12)
+	require.Equal(t, int64(12), receiveHeight(t, heights))
+}
+
+func TestNewBlockHeightsSilentSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	querier := &heightQuerier{height: 20}
+	heights := newBlockHeights(ctx, &chanSubscriber{heights: make(chan int64)}, querier, 10*time.Millisecond)
+	require.Equal(t, int64(20), receiveHeight(t, heights))
+
+	closed := make(chan int64)
+	close(closed)
+	heights = newBlockHeights(ctx, &chanSubscriber{heights: closed}, querier, 10*time.Millisecond)
+	require.Equal(t, int64(20), receiveHeight(t, heights))
+}
@@ -2,6 +2,7 @@ package bridge
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path"
@@ -11,8 +12,8 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	fxtronbridge "github.com/functionx/fx-tron-bridge"
-	"github.com/functionx/fx-tron-bridge/client"
 	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
 	"github.com/functionx/fx-tron-bridge/internal/utils"
 )
 
@@ -23,7 +24,7 @@ type Oracle struct {
 }
 
 func NewOracle(fxBridge *FxTronBridge, startBlockNumber uint64) (*Oracle, error) {
-	lastBlockNumber, err := fxBridge.CrossChainClient.LastEventBlockHeightByAddr(fxBridge.GetBridgerAddr().String(), fxtronbridge.Tron)
+	lastBlockNumber, err := fxBridge.Querier.LastEventBlockHeightByAddr(fxBridge.GetBridgerAddr().String(), fxtronbridge.Tron)
 	logger.Infof("new oracle start block number: %d, fx core last block number: %d", startBlockNumber, lastBlockNumber)
 	if err != nil {
 		logger.Errorf("get last block number fail bridger address: %s, err: %s", fxBridge.GetBridgerAddr().String(), err.Error())
@@ -39,7 +40,7 @@ func NewOracle(fxBridge *FxTronBridge, startBlockNumber uint64) (*Oracle, error)
 	}
 
 	if lastBlockNumber <= 0 {
-		lastBlockNumber, err = getLastBlockNumber(fxBridge.BridgeAddr, fxBridge.TronClient)
+		lastBlockNumber, err = getLastBlockNumber(fxBridge.BridgeAddr, fxBridge.EventSource)
 		if err != nil {
 			return nil, err
 		}
@@ -57,8 +58,8 @@ func NewOracle(fxBridge *FxTronBridge, startBlockNumber uint64) (*Oracle, error)
 	}, nil
 }
 
-func getLastBlockNumber(bridgeAddr string, tronClient *client.TronClient) (uint64, error) {
-	latestBlockNumber, err := tronClient.BlockNumber(context.Background())
+func getLastBlockNumber(bridgeAddr string, eventSource EventSource) (uint64, error) {
+	latestBlockNumber, err := eventSource.BlockNumber(context.Background())
 	if err != nil {
 		logger.Errorf("get tron last block number fail err: %s", err.Error())
 		return 0, err
@@ -67,7 +68,7 @@ func getLastBlockNumber(bridgeAddr string, tronClient *client.TronClient) (uint6
 	minBlockNumber := latestBlockNumber - 1000
 	for i := latestBlockNumber; i > minBlockNumber; i-- {
 		logger.Infof("get tron last block number current blockNumber: %d", i)
-		oracleSetUpdatedEvents, err := tronClient.QueryOracleSetUpdatedEvent(bridgeAddr, i)
+		oracleSetUpdatedEvents, err := eventSource.QueryOracleSetUpdatedEvent(bridgeAddr, i)
 		if err != nil {
 			logger.Errorf("query oracle set updated event fail bridgeAddr: %s, blockNumber: %d, err: %s", bridgeAddr, i, err.Error())
 			return 0, err
@@ -80,31 +81,34 @@ func getLastBlockNumber(bridgeAddr string, tronClient *client.TronClient) (uint6
 }
 
 func (o *Oracle) bridgeEvent() error {
-	bridger, err := o.CrossChainClient.GetOracleByBridgerAddr(o.GetBridgerAddr().String(), fxtronbridge.Tron)
+	bridger, err := o.Querier.GetOracleByBridgerAddr(o.GetBridgerAddr().String(), fxtronbridge.Tron)
 	if err != nil {
 		logger.Errorf("get oracle by bridger fail bridger: %s, err: %s", o.GetBridgerAddr().String(), err.Error())
 		return err
 	}
-	if bridger.Online {
+	if !bridger.Online {
 		logger.Warn("get oracle status is not active bridger: %v", bridger)
+		notifier.Fire(notifier.AlertOracleInactive, "oracle is not active bridger: %s", bridger.BridgerAddress)
 		return nil
 	}
-	lastEventNonce, err := o.CrossChainClient.LastEventNonceByAddr(o.GetBridgerAddr().String(), fxtronbridge.Tron)
+	notifier.Resolve(notifier.AlertOracleInactive)
+	lastEventNonce, err := o.Querier.LastEventNonceByAddr(o.GetBridgerAddr().String(), fxtronbridge.Tron)
 	if err != nil {
-		logger.Errorf("get last event nonce by addr fail orcAddr: %s, err: %s", o.GetBridgerAddr().String(), err.Error())
+		logger.Errorw("get last event nonce by addr fail", "bridger_addr", o.GetBridgerAddr().String(), "error", err)
 		return err
 	}
 	o.lastEventNonce = lastEventNonce
-	latestBlockNumber, err := o.TronClient.BlockNumber(context.Background())
+	latestBlockNumber, err := o.EventSource.BlockNumber(context.Background())
 	if err != nil {
 		logger.Errorf("get last block number fail err: %s", err.Error())
 		return err
 	}
 	endBlockNumber := latestBlockNumber - fxtronbridge.TronBlockDelay
-	logger.Infof("oracle handle event startBlockNumber: %d, endBlockNumber: %d, lastEventNonce: %d", o.startBlockNumber, endBlockNumber, o.lastEventNonce)
+	logger.Infow("oracle handle event", "start_block_number", o.startBlockNumber, "end_block_number", endBlockNumber, "event_nonce", o.lastEventNonce)
 
 	fxtronbridge.BlockHeightProm.Set(float64(o.startBlockNumber))
 	if o.startBlockNumber >= endBlockNumber {
+		notifier.Resolve(notifier.AlertBridgeBehind)
 		return nil
 	}
 
@@ -112,15 +116,18 @@ func (o *Oracle) bridgeEvent() error {
 	fxtronbridge.BlockIntervalProm.Set(float64(blockNumberInterval))
 
 	if blockNumberInterval > fxtronbridge.TronDelayBlockWarn {
-		logger.Warnf("bridge behind too much block number startBlockNumber: %d, endBlockNumber: %d", o.startBlockNumber, endBlockNumber)
+		logger.Warnw("bridge behind too much block number", "start_block_number", o.startBlockNumber, "end_block_number", endBlockNumber)
+		notifier.Fire(notifier.AlertBridgeBehind, "bridge behind %d blocks, start block number: %d, end block number: %d", blockNumberInterval, o.startBlockNumber, endBlockNumber)
+	} else {
+		notifier.Resolve(notifier.AlertBridgeBehind)
 	}
 
 	msgs := make([]sdk.Msg, 0)
 	batchBlockNumber := 0
 	for blockNumber := o.startBlockNumber + 1; blockNumber <= endBlockNumber; blockNumber++ {
-		events, err := o.TronClient.QueryBlockEvent(o.BridgeAddr, blockNumber)
+		events, err := o.EventSource.QueryBlockEvent(o.BridgeAddr, blockNumber)
 		if err != nil {
-			logger.Errorf("query block event fail bridgeAddr: %s, blockNumber: %d, err: %s", o.BridgeAddr, blockNumber, err.Error())
+			logger.Errorw("query block event fail", "bridge_addr", o.BridgeAddr, "block_number", blockNumber, "error", err)
 			return err
 		}
 		sort.Slice(events, func(i, j int) bool {
@@ -130,13 +137,19 @@ func (o *Oracle) bridgeEvent() error {
 			if event.GetEventNonce() <= lastEventNonce {
 				continue
 			}
+			logger.Infow("oracle claim event", "block_number", blockNumber, "event_nonce", event.GetEventNonce(), "tx_hash", hex.EncodeToString(event.GetTxHash().Bytes()))
 			msgs = append(msgs, event.ToMsg(blockNumber, o.GetBridgerAddr().String()))
 		}
 
 		if len(msgs) > fxtronbridge.BatchSendMsgCount || batchBlockNumber > 100 || blockNumber == endBlockNumber {
+			fxtronbridge.MsgPendingLenProm.Set(float64(len(msgs)))
 			if err = o.BatchSendMsg(msgs, fxtronbridge.BatchSendMsgCount); err != nil {
 				return err
 			}
+			for _, msg := range msgs {
+				fxtronbridge.ClaimSentProm.WithLabelValues(sdk.MsgTypeURL(msg)).Inc()
+			}
+			fxtronbridge.MsgPendingLenProm.Set(0)
 			o.startBlockNumber = blockNumber
 			_ = saveLastBlockNumber(o.startBlockNumber)
 			batchBlockNumber = 0
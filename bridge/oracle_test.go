@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+const testBridgeAddr = "TVSMxNVuhzHTCvcnPzFmyAn2B2iDQjdgQh"
+
+func newTestFxTronBridge(t *testing.T) (*FxTronBridge, *testutil.FakeTron, *testutil.FakeFx) {
+	t.Setenv("HOME", t.TempDir())
+	fakeTron := testutil.NewFakeTron(t)
+	fakeFx := testutil.NewFakeFx(t)
+	tronPrivKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fxBridge, err := NewGrpcFxTronBridge(testBridgeAddr, fakeTron.URL, fakeFx.URL, fxtronbridge.GrpcOptions{}, fxtronbridge.GrpcOptions{}, secp256k1.GenPrivKey(), tronPrivKey)
+	require.NoError(t, err)
+	fakeFx.Update(func(state *testutil.FxState) {
+		state.Params = crosschaintypes.Params{GravityId: "tron"}
+		state.Oracle = &crosschaintypes.Oracle{
+			BridgerAddress:  fxBridge.GetBridgerAddr().String(),
+			ExternalAddress: fxBridge.GetTronAddr().String(),
+			Online:          true,
+		}
+	})
+	return fxBridge, fakeTron, fakeFx
+}
+
+func TestOracleBridgeEvent(t *testing.T) {
+	fxBridge, fakeTron, fakeFx := newTestFxTronBridge(t)
+	fakeFx.Update(func(state *testutil.FxState) {
+		state.LastEventNonce = 1
+	})
+	fakeTron.SetBlockNumber(100)
+
+	token := ethcommon.HexToAddress("0x8a21bcef7269bd328bf843207bfe0d84dc3b68e9")
+	sender := ethcommon.HexToAddress("0x1f1b1e8a2b4ab8a1ce0fdc2a5e7e6a6c0c9cfd45")
+	var destination [32]byte
+	copy(destination[12:], fxBridge.GetBridgerAddr())
+	// nonce 1 is already observed by fx core, only nonce 2 must be claimed
+	require.NoError(t, fakeTron.AddEvent(60, testBridgeAddr, []byte{0x01}, "SendToFxEvent",
+		token, sender, destination, [32]byte{}, big.NewInt(100), big.NewInt(1)))
+	require.NoError(t, fakeTron.AddEvent(70, testBridgeAddr, []byte{0x02}, "SendToFxEvent",
+		token, sender, destination, [32]byte{}, big.NewInt(200), big.NewInt(2)))
+	// events after the confirmation delay are not handled yet
+	require.NoError(t, fakeTron.AddEvent(80, testBridgeAddr, []byte{0x03}, "SendToFxEvent",
+		token, sender, destination, [32]byte{}, big.NewInt(300), big.NewInt(3)))
+
+	oracle := &Oracle{FxTronBridge: fxBridge, startBlockNumber: 50}
+	require.NoError(t, oracle.bridgeEvent())
+
+	msgs := fakeFx.MsgsByType(&crosschaintypes.MsgSendToFxClaim{})
+	require.Len(t, msgs, 1)
+	var claim crosschaintypes.MsgSendToFxClaim
+	require.NoError(t, proto.Unmarshal(msgs[0].Value, &claim))
+	require.Equal(t, uint64(2), claim.EventNonce)
+	require.Equal(t, uint64(70), claim.BlockHeight)
+	require.Equal(t, "200", claim.Amount.String())
+	require.Equal(t, fxBridge.GetBridgerAddr().String(), claim.Receiver)
+	require.Equal(t, uint64(100-25), oracle.startBlockNumber)
+}
@@ -1,13 +1,14 @@
 package bridge
 
 import (
+	"context"
 	"time"
 
 	fxtronbridge "github.com/functionx/fx-tron-bridge"
 	"github.com/functionx/fx-tron-bridge/internal/logger"
 )
 
-func Run(fxBridge *FxTronBridge, startBlockNumber uint64, fees string) error {
+func Run(fxBridge *FxTronBridge, startBlockNumber uint64, fees string, feeBalanceWarn, solvencyThreshold float64) error {
 	if startBlockNumber > 0 {
 		startBlockNumber--
 	}
@@ -20,17 +21,57 @@ func Run(fxBridge *FxTronBridge, startBlockNumber uint64, fees string) error {
 		return err
 	}
 
+	driftDetector := NewDriftDetector(fxBridge)
+	batchWatchdog := NewBatchWatchdog(fxBridge, fxtronbridge.BatchTimeoutWarnBlocks)
+
+	go runSinger(context.Background(), singer, newBlockHeights(context.Background(), fxBridge.BlockSubscriber, fxBridge.Querier, fxtronbridge.FxAvgBlockMillisecond))
+
 	eventHandlerTicker := time.NewTicker(fxtronbridge.FxAvgBlockMillisecond)
-	for range eventHandlerTicker.C {
-		if err = oracle.bridgeEvent(); err != nil {
-			logger.Errorf("bridge oracle error: %s", err)
-		}
+	driftTicker := time.NewTicker(fxtronbridge.OracleSetDriftCheckInterval)
+	solvencyTicker := time.NewTicker(fxtronbridge.SolvencyCheckInterval)
+	batchTimeoutTicker := time.NewTicker(fxtronbridge.BatchTimeoutCheckInterval)
+	for {
+		select {
+		case <-eventHandlerTicker.C:
+			if err = oracle.bridgeEvent(); err != nil {
+				logger.Errorf("bridge oracle error: %s", err)
+			}
 
-		if err = singer.confirm(); err != nil {
-			logger.Errorf("bridge confirm error: %s", err)
+			fxBridge.setFxKeyBalanceMetrics(fees, feeBalanceWarn)
+		case <-driftTicker.C:
+			if err = driftDetector.check(); err != nil {
+				logger.Errorf("oracle set drift check error: %s", err)
+			}
+		case <-solvencyTicker.C:
+			if err = fxBridge.checkSolvency(solvencyThreshold); err != nil {
+				logger.Errorf("solvency check error: %s", err)
+			}
+		case <-batchTimeoutTicker.C:
+			if err = batchWatchdog.check(); err != nil {
+				logger.Errorf("batch timeout check error: %s", err)
+			}
 		}
+	}
+}
 
-		fxBridge.setFxKeyBalanceMetrics(fees)
+// runSinger confirms the pending oracle sets and batches as soon as a new fx core block is committed, apart
+// from the oracle loop so a slow tron catch up does not delay the signatures.
+func runSinger(ctx context.Context, singer *Singer, heights <-chan int64) {
+	paramsTicker := time.NewTicker(fxtronbridge.ParamsRefreshInterval)
+	defer paramsTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case height := <-heights:
+			logger.Debugw("singer new fx block", "height", height)
+			if err := singer.confirm(); err != nil {
+				logger.Errorf("bridge confirm error: %s", err)
+			}
+		case <-paramsTicker.C:
+			if err := singer.refreshParams(); err != nil {
+				logger.Errorf("singer refresh params error: %s", err)
+			}
+		}
 	}
-	return nil
 }
@@ -2,134 +2,325 @@ package bridge
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
 	"sort"
+	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"go.uber.org/multierr"
 
 	fxtronbridge "github.com/functionx/fx-tron-bridge"
 	"github.com/functionx/fx-tron-bridge/contract"
 	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+	"github.com/functionx/fx-tron-bridge/internal/protection"
 )
 
 type Singer struct {
 	*FxTronBridge
-	gravityId string
-	fees      string
+	gravityId    string
+	signedWindow uint64
+	fees         string
+	signErrCount int
+	protection   *protection.Store
+}
+
+// signConfirm is a signed confirmation waiting to be sent, deadline is the fx core block height
+// after which the oracle is slashed for not having confirmed the object.
+type signConfirm struct {
+	msg      sdk.Msg
+	kind     string
+	token    string
+	nonce    uint64
+	deadline uint64
 }
 
 func NewSinger(fxBridge *FxTronBridge, fees string) (*Singer, error) {
-	params, err := fxBridge.CrossChainClient.Params(fxtronbridge.Tron)
+	params, err := fxBridge.Querier.Params(fxtronbridge.Tron)
 	if err != nil {
 		logger.Errorf("get gravityId fail err: %s", err.Error())
 		return nil, err
 	}
+	store, err := protection.OpenLocked(path.Join(os.ExpandEnv(fxtronbridge.TronHome), protection.FileName), fxBridge.GetTronAddr().String())
+	if err != nil {
+		logger.Errorf("open slashing protection fail err: %s", err.Error())
+		return nil, err
+	}
 	return &Singer{
 		FxTronBridge: fxBridge,
 		gravityId:    params.GravityId,
+		signedWindow: params.SignedWindow,
 		fees:         fees,
+		protection:   store,
 	}, nil
 }
 
-func (s Singer) confirm() error {
-	bridger, err := s.CrossChainClient.GetOracleByBridgerAddr(s.GetBridgerAddr().String(), fxtronbridge.Tron)
+// refreshParams reloads the crosschain params, so governance changes apply without a restart.
+func (s *Singer) refreshParams() error {
+	params, err := s.Querier.Params(fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	if params.GravityId != s.gravityId || params.SignedWindow != s.signedWindow {
+		logger.Infow("crosschain params changed", "gravity_id", params.GravityId, "signed_window", params.SignedWindow,
+			"old_gravity_id", s.gravityId, "old_signed_window", s.signedWindow)
+	}
+	s.gravityId, s.signedWindow = params.GravityId, params.SignedWindow
+	return nil
+}
+
+func (s *Singer) confirm() error {
+	bridger, err := s.Querier.GetOracleByBridgerAddr(s.GetBridgerAddr().String(), fxtronbridge.Tron)
 	if err != nil {
 		return err
 	}
-	if bridger.Online {
+	if !bridger.Online {
 		logger.Warnf("get oracle by bridger status is not active bridger: %v", bridger)
+		notifier.Fire(notifier.AlertOracleInactive, "oracle is not active bridger: %s", bridger.BridgerAddress)
 		return nil
 	}
+	notifier.Resolve(notifier.AlertOracleInactive)
 	if bridger.ExternalAddress != s.GetTronAddr().String() {
-		panic("invalid tron private key, expect " + bridger.ExternalAddress)
+		notifier.Fire(notifier.AlertKeyMismatch, "invalid tron private key, expect: %s, actual: %s", bridger.ExternalAddress, s.GetTronAddr().String())
+		return fmt.Errorf("invalid tron private key, expect %s", bridger.ExternalAddress)
 	}
+	notifier.Resolve(notifier.AlertKeyMismatch)
 	logger.Debugf("confirm bridger address: %s", bridger.BridgerAddress)
 
-	if err = s.singerOracleSetConfirm(); err != nil {
+	var signErr error
+	oracleSetConfirms, err := s.singerOracleSetConfirm()
+	if err != nil {
 		logger.Errorf("singer oracle_set confirm error: %s", err.Error())
+		signErr = err
 	}
 
-	if err = s.singerConfirmBatch(); err != nil {
+	batchConfirms, err := s.singerConfirmBatch()
+	if err != nil {
 		logger.Errorf("singer confirm batch error: %s", err.Error())
+		signErr = err
+	}
+
+	if err = s.sendConfirms(append(oracleSetConfirms, batchConfirms...)); err != nil {
+		logger.Errorf("singer send confirm error: %s", err.Error())
+		signErr = err
 	}
+	s.checkSignError(signErr)
 	return nil
 }
 
-func (s *Singer) singerConfirmBatch() error {
-	txBatch, err := s.CrossChainClient.LastPendingBatchRequestByAddr(s.GetBridgerAddr().String(), fxtronbridge.Tron)
+// sendConfirms exports the blocks left before every deadline and sends the confirms closest to their deadline first.
+func (s *Singer) sendConfirms(confirms []signConfirm) error {
+	fxtronbridge.SignWindowBlocksProm.Reset()
+	if len(confirms) <= 0 {
+		return nil
+	}
+	fxBlock, err := s.Querier.GetLatestBlock()
 	if err != nil {
-		logger.Errorf("get last pending batch request by addr fail orcAddr: %s, err: %s", s.GetBridgerAddr().String(), err.Error())
 		return err
 	}
-	if txBatch == nil {
-		return nil
+	sort.SliceStable(confirms, func(i, j int) bool {
+		return confirms[i].deadline < confirms[j].deadline
+	})
+	msgs := make([]sdk.Msg, 0, len(confirms))
+	for _, confirm := range confirms {
+		var remaining uint64
+		if confirm.deadline > uint64(fxBlock.Header.Height) {
+			remaining = confirm.deadline - uint64(fxBlock.Header.Height)
+		}
+		fxtronbridge.SignWindowBlocksProm.WithLabelValues(confirm.kind, confirm.token, strconv.FormatUint(confirm.nonce, 10)).Set(float64(remaining))
+		logger.Debugw("singer confirm deadline", "kind", confirm.kind, "token", confirm.token, "nonce", confirm.nonce,
+			"deadline", confirm.deadline, "remaining_blocks", remaining)
+		msgs = append(msgs, confirm.msg)
+	}
+	if err = s.BatchSendMsg(msgs, fxtronbridge.BatchSendMsgCount); err != nil {
+		return err
 	}
-	logger.Infof("singer confirm batch tokenContract: %s, batchNonce: %d, blockHeight: %d", txBatch.TokenContract, txBatch.BatchNonce, txBatch.Block)
+	for _, confirm := range confirms {
+		if confirm.kind == protection.KindOracleSet {
+			fxtronbridge.FxUpdateOracleSetProm.Inc()
+		} else {
+			fxtronbridge.FxSubmitBatchSignProm.WithLabelValues(confirm.token).Inc()
+		}
+	}
+	return nil
+}
+
+func (s *Singer) checkSignError(err error) {
+	if err == nil {
+		s.signErrCount = 0
+		notifier.Resolve(notifier.AlertSignError)
+		return
+	}
+	s.signErrCount++
+	if s.signErrCount >= fxtronbridge.SignErrorAlertCount {
+		notifier.Fire(notifier.AlertSignError, "singer failed %d times in a row, last error: %s", s.signErrCount, err.Error())
+	}
+}
 
-	confirmBatchHash, err := contract.EncodeConfirmBatchHash(s.gravityId, *txBatch)
+func (s *Singer) singerConfirmBatch() ([]signConfirm, error) {
+	txBatches, err := s.Querier.OutgoingTxBatches(fxtronbridge.Tron)
 	if err != nil {
-		logger.Errorf("singer confirm batch encodeConfirmBatchHash fail txBatch: %s, err: %s", txBatch.String(), err.Error())
-		return err
+		logger.Errorf("get outgoing tx batches fail err: %s", err.Error())
+		return nil, err
 	}
-	sign, err := crypto.Sign(confirmBatchHash, s.TronPrivKey)
+	// oldest first, so a batch close to its timeout is never queued behind newer ones
+	sort.Slice(txBatches, func(i, j int) bool {
+		if txBatches[i].Block != txBatches[j].Block {
+			return txBatches[i].Block < txBatches[j].Block
+		}
+		return txBatches[i].BatchNonce < txBatches[j].BatchNonce
+	})
+
+	// a batch that fails is skipped, so it never holds back the batches sorted after it
+	var confirmErr error
+	confirms := make([]signConfirm, 0)
+	for _, txBatch := range txBatches {
+		confirmed, err := s.batchConfirmed(txBatch)
+		if err != nil {
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+		if confirmed {
+			continue
+		}
+		logger.Infow("singer confirm batch", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "block_number", txBatch.Block)
+
+		if err = verifyBatch(s.BridgeState, s.BridgeAddr, txBatch); err != nil {
+			var verifyErr *VerifyError
+			if errors.As(err, &verifyErr) {
+				logger.Warnw("skip invalid batch", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "reason", verifyErr.Reason, "error", verifyErr.Message)
+				fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindBatch, verifyErr.Reason).Inc()
+				continue
+			}
+			logger.Errorw("verify batch fail", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "error", err)
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+
+		confirmBatchHash, err := contract.EncodeConfirmBatchHash(s.gravityId, *txBatch)
+		if err != nil {
+			logger.Errorw("singer confirm batch encodeConfirmBatchHash fail", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "error", err)
+			fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindBatch, "encode_error").Inc()
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+		if err = s.protect(protection.KindBatch, txBatch.TokenContract, txBatch.BatchNonce, confirmBatchHash); err != nil {
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+		sign, err := crypto.Sign(confirmBatchHash, s.TronPrivKey)
+		if err != nil {
+			logger.Errorw("singer confirm batch sign fail", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "error", err)
+			fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindBatch, "sign_error").Inc()
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+		confirms = append(confirms, signConfirm{
+			msg: &crosschaintypes.MsgConfirmBatch{
+				Nonce:           txBatch.BatchNonce,
+				TokenContract:   txBatch.TokenContract,
+				BridgerAddress:  s.GetBridgerAddr().String(),
+				ExternalAddress: s.GetTronAddr().String(),
+				Signature:       hex.EncodeToString(sign),
+				ChainName:       fxtronbridge.Tron,
+			},
+			kind:     protection.KindBatch,
+			token:    txBatch.TokenContract,
+			nonce:    txBatch.BatchNonce,
+			deadline: txBatch.Block + s.signedWindow,
+		})
+	}
+	return confirms, confirmErr
+}
+
+func (s *Singer) batchConfirmed(txBatch *crosschaintypes.OutgoingTxBatch) (bool, error) {
+	confirms, err := s.Querier.BatchConfirms(txBatch.BatchNonce, txBatch.TokenContract, fxtronbridge.Tron)
 	if err != nil {
-		logger.Errorf("singer confirm batch sign fail err: %s", err.Error())
-		return err
+		logger.Errorw("get batch confirms fail", "token", txBatch.TokenContract, "batch_nonce", txBatch.BatchNonce, "error", err)
+		return false, err
+	}
+	for _, confirm := range confirms {
+		if confirm.BridgerAddress == s.GetBridgerAddr().String() {
+			return true, nil
+		}
 	}
-	return s.BatchSendMsg([]sdk.Msg{
-		&crosschaintypes.MsgConfirmBatch{
-			Nonce:           txBatch.BatchNonce,
-			TokenContract:   txBatch.TokenContract,
-			BridgerAddress:  s.GetBridgerAddr().String(),
-			ExternalAddress: s.GetTronAddr().String(),
-			Signature:       hex.EncodeToString(sign),
-			ChainName:       fxtronbridge.Tron,
-		}}, fxtronbridge.BatchSendMsgCount)
+	return false, nil
 }
 
-type IMsg interface {
-	sdk.Msg
-	GetNonce() uint64
+// protect records the digest in the slashing-protection store, it must succeed before the digest is signed.
+func (s *Singer) protect(kind, token string, nonce uint64, digest []byte) error {
+	err := s.protection.CheckAndRecord(kind, token, nonce, digest)
+	if errors.Is(err, protection.ErrConflictingDigest) {
+		logger.Errorw("refuse to sign conflicting digest", "kind", kind, "token", token, "nonce", nonce, "error", err)
+		fxtronbridge.SignSkippedProm.WithLabelValues(kind, "conflicting_digest").Inc()
+		notifier.Fire(notifier.AlertSlashingProtection, "refuse to sign %s nonce %d token %s, a different digest is already signed", kind, nonce, token)
+	} else if err != nil {
+		logger.Errorw("record signed digest fail", "kind", kind, "token", token, "nonce", nonce, "error", err)
+		fxtronbridge.SignSkippedProm.WithLabelValues(kind, "protection_error").Inc()
+	}
+	return err
 }
 
-func (s *Singer) singerOracleSetConfirm() error {
-	oracleSet, err := s.CrossChainClient.LastPendingOracleSetRequestByAddr(s.GetBridgerAddr().String(), fxtronbridge.Tron)
+func (s *Singer) singerOracleSetConfirm() ([]signConfirm, error) {
+	oracleSet, err := s.Querier.LastPendingOracleSetRequestByAddr(s.GetBridgerAddr().String(), fxtronbridge.Tron)
 	if err != nil {
 		logger.Errorf("get last pending oracle set request by addr fail orcAddr: %s, err: %s", s.GetBridgerAddr().String(), err.Error())
-		return err
+		return nil, err
 	}
 	if len(oracleSet) <= 0 {
-		return nil
+		return nil, nil
 	}
-	logger.Infof("singer oracle set confirm oracle set len: %d, oracle first nonce: %d, bridger address: %s", len(oracleSet), oracleSet[0].Nonce, s.GetBridgerAddr().String())
+	logger.Infow("singer oracle set confirm", "oracle_set_len", len(oracleSet), "oracle_set_nonce", oracleSet[0].Nonce, "bridger_addr", s.GetBridgerAddr().String())
 
-	iMsgs := make([]IMsg, 0)
+	var confirmErr error
+	confirms := make([]signConfirm, 0)
 	for _, oracle := range oracleSet {
+		if err = verifyOracleSet(s.BridgeState, s.Querier, s.BridgeAddr, oracle); err != nil {
+			var verifyErr *VerifyError
+			if errors.As(err, &verifyErr) {
+				logger.Warnw("skip invalid oracle set", "oracle_set_nonce", oracle.Nonce, "reason", verifyErr.Reason, "error", verifyErr.Message)
+				fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindOracleSet, verifyErr.Reason).Inc()
+				continue
+			}
+			logger.Errorw("verify oracle set fail", "oracle_set_nonce", oracle.Nonce, "error", err)
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
 		hash, err := contract.EncodeOracleSetConfirmHash(s.gravityId, *oracle)
 		if err != nil {
-			logger.Errorf("singer oracle set confirm encodeOracleSetConfirmHash fail oracle: %s, err: %s", oracle, err.Error())
-			return err
+			logger.Errorw("singer oracle set confirm encodeOracleSetConfirmHash fail", "oracle_set_nonce", oracle.Nonce, "error", err)
+			fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindOracleSet, "encode_error").Inc()
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
+		}
+		if err = s.protect(protection.KindOracleSet, "", oracle.Nonce, hash); err != nil {
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
 		}
 		sign, err := crypto.Sign(hash, s.TronPrivKey)
 		if err != nil {
-			logger.Errorf("singer oracle set confirm sign fail err: %s", err.Error())
-			return err
+			logger.Errorw("singer oracle set confirm sign fail", "oracle_set_nonce", oracle.Nonce, "error", err)
+			fxtronbridge.SignSkippedProm.WithLabelValues(protection.KindOracleSet, "sign_error").Inc()
+			confirmErr = multierr.Append(confirmErr, err)
+			continue
 		}
-		iMsgs = append(iMsgs, &crosschaintypes.MsgOracleSetConfirm{
-			Nonce:           oracle.Nonce,
-			BridgerAddress:  s.GetBridgerAddr().String(),
-			ExternalAddress: s.GetTronAddr().String(),
-			Signature:       hex.EncodeToString(sign),
-			ChainName:       fxtronbridge.Tron,
+		confirms = append(confirms, signConfirm{
+			msg: &crosschaintypes.MsgOracleSetConfirm{
+				Nonce:           oracle.Nonce,
+				BridgerAddress:  s.GetBridgerAddr().String(),
+				ExternalAddress: s.GetTronAddr().String(),
+				Signature:       hex.EncodeToString(sign),
+				ChainName:       fxtronbridge.Tron,
+			},
+			kind:     protection.KindOracleSet,
+			nonce:    oracle.Nonce,
+			deadline: oracle.Height + s.signedWindow,
 		})
 	}
-	sort.Slice(iMsgs, func(i, j int) bool {
-		return iMsgs[i].GetNonce() < iMsgs[j].GetNonce()
-	})
-	msgs := make([]sdk.Msg, 0)
-	for _, imsg := range iMsgs {
-		msgs = append(msgs, imsg.(sdk.Msg))
-	}
-	return s.BatchSendMsg(msgs, fxtronbridge.BatchSendMsgCount)
+	return confirms, confirmErr
 }
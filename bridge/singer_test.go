@@ -0,0 +1,86 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func requireSignedBy(t *testing.T, expect address.Address, hash []byte, signature string) {
+	sign, err := hex.DecodeString(signature)
+	require.NoError(t, err)
+	pubKey, err := crypto.SigToPub(hash, sign)
+	require.NoError(t, err)
+	require.Equal(t, expect.String(), address.PubkeyToAddress(*pubKey).String())
+}
+
+func TestSingerConfirm(t *testing.T) {
+	fxBridge, fakeTron, fakeFx := newTestFxTronBridge(t)
+	fakeTron.SetBlockNumber(100)
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "getBridgeTokenList", []contract.FxBridgeToken{newTestBridgeToken(t, "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR")}))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "tokenStatus", false, true, true))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "lastBatchNonce", big.NewInt(2)))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_lastOracleSetNonce", big.NewInt(1)))
+	oracleSet := &crosschaintypes.OracleSet{
+		Nonce:   2,
+		Members: crosschaintypes.BridgeValidators{{Power: fxtronbridge.ThresholdVotePower, ExternalAddress: fxBridge.GetTronAddr().String()}},
+		Height:  10,
+	}
+	txBatch := &crosschaintypes.OutgoingTxBatch{
+		BatchNonce:   3,
+		BatchTimeout: 1000,
+		Transactions: []*crosschaintypes.OutgoingTransferTx{
+			{
+				Id:          1,
+				Sender:      fxBridge.GetBridgerAddr().String(),
+				DestAddress: "TFysCB929XGezbnyumoFScyevjDggu3BPq",
+				Token:       crosschaintypes.ERC20Token{Contract: "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", Amount: sdk.NewInt(2000)},
+				Fee:         crosschaintypes.ERC20Token{Contract: "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", Amount: sdk.NewInt(10)},
+			},
+		},
+		TokenContract: "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR",
+		Block:         9,
+		FeeReceive:    "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f",
+	}
+	fakeFx.Update(func(state *testutil.FxState) {
+		state.OracleSets = []*crosschaintypes.OracleSet{oracleSet}
+		state.PendingOracleSets = []*crosschaintypes.OracleSet{oracleSet}
+		state.Batches = []*crosschaintypes.OutgoingTxBatch{txBatch}
+	})
+
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	// everything is confirmed, the second pass must not sign again
+	require.NoError(t, singer.confirm())
+
+	var oracleSetConfirms []*crosschaintypes.MsgOracleSetConfirm
+	var batchConfirms []*crosschaintypes.MsgConfirmBatch
+	fakeFx.Update(func(state *testutil.FxState) {
+		oracleSetConfirms = state.OracleSetConfirms
+		batchConfirms = state.BatchConfirms
+	})
+
+	require.Len(t, oracleSetConfirms, 1)
+	require.Equal(t, oracleSet.Nonce, oracleSetConfirms[0].Nonce)
+	oracleSetHash, err := contract.EncodeOracleSetConfirmHash("tron", *oracleSet)
+	require.NoError(t, err)
+	requireSignedBy(t, fxBridge.GetTronAddr(), oracleSetHash, oracleSetConfirms[0].Signature)
+
+	require.Len(t, batchConfirms, 1)
+	require.Equal(t, txBatch.BatchNonce, batchConfirms[0].Nonce)
+	require.Equal(t, txBatch.TokenContract, batchConfirms[0].TokenContract)
+	batchHash, err := contract.EncodeConfirmBatchHash("tron", *txBatch)
+	require.NoError(t, err)
+	requireSignedBy(t, fxBridge.GetTronAddr(), batchHash, batchConfirms[0].Signature)
+}
@@ -0,0 +1,125 @@
+package bridge
+
+import (
+	"fmt"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+// SolvencyReport compares the tokens locked by the bridge contract with the fx core supply they back.
+type SolvencyReport struct {
+	Token   string
+	Symbol  string
+	Denom   string
+	Locked  *big.Int
+	Supply  sdk.Int
+	Pending sdk.Int
+	// Diff is Locked - (Supply + Pending), DiffRatio is Diff relative to Supply + Pending
+	Diff      *big.Int
+	DiffRatio float64
+	// Skipped is set for fx core originated tokens, they are minted by the contract and not locked
+	Skipped bool
+}
+
+func (r SolvencyReport) Backed() *big.Int {
+	return new(big.Int).Add(r.Supply.BigInt(), r.Pending.BigInt())
+}
+
+// Reconcile builds the solvency report of every bridge token. Txs still in the fx core outgoing pool
+// are not part of a batch yet and can not be queried, so they show up as a small surplus.
+func (f *FxTronBridge) Reconcile() ([]SolvencyReport, error) {
+	bridgeTokens, err := f.BridgeState.GetBridgeTokenList(f.BridgeAddr)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := f.Querier.OutgoingTxBatches(fxtronbridge.Tron)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[string]sdk.Int)
+	for _, batch := range batches {
+		for _, transferTx := range batch.Transactions {
+			amount, ok := pending[batch.TokenContract]
+			if !ok {
+				amount = sdk.ZeroInt()
+			}
+			pending[batch.TokenContract] = amount.Add(transferTx.Token.Amount).Add(transferTx.Fee.Amount)
+		}
+	}
+
+	reports := make([]SolvencyReport, 0, len(bridgeTokens))
+	for _, bridgeToken := range bridgeTokens {
+		token := contract.AddressToString(bridgeToken.Addr)
+		report := SolvencyReport{Token: token, Symbol: bridgeToken.Symbol, Supply: sdk.ZeroInt(), Pending: sdk.ZeroInt()}
+		isOriginated, _, _, err := f.BridgeState.GetTokenStatus(f.BridgeAddr, token)
+		if err != nil {
+			return nil, err
+		}
+		if isOriginated {
+			report.Skipped = true
+			reports = append(reports, report)
+			continue
+		}
+		denom, err := f.Querier.TokenToDenom(token, fxtronbridge.Tron)
+		if err != nil {
+			return nil, fmt.Errorf("token to denom token: %s, err: %w", token, err)
+		}
+		report.Denom = denom.Denom
+		supply, err := f.Querier.SupplyOf(report.Denom)
+		if err != nil {
+			return nil, err
+		}
+		report.Supply = supply.Amount
+		if amount, ok := pending[token]; ok {
+			report.Pending = amount
+		}
+		report.Locked, err = f.BridgeState.BalanceOf(token, f.BridgeAddr)
+		if err != nil {
+			return nil, err
+		}
+		backed := report.Backed()
+		report.Diff = new(big.Int).Sub(report.Locked, backed)
+		if backed.Sign() > 0 {
+			report.DiffRatio, _ = new(big.Float).Quo(new(big.Float).SetInt(report.Diff), new(big.Float).SetInt(backed)).Float64()
+		} else if report.Diff.Sign() != 0 {
+			report.DiffRatio = 1
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// checkSolvency reconciles the bridge tokens and alerts for every token whose discrepancy is beyond threshold.
+func (f *FxTronBridge) checkSolvency(threshold float64) error {
+	reports, err := f.Reconcile()
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		if report.Skipped {
+			continue
+		}
+		locked, _ := new(big.Float).SetInt(report.Locked).Float64()
+		backed, _ := new(big.Float).SetInt(report.Backed()).Float64()
+		fxtronbridge.TokenLockedProm.WithLabelValues(report.Token).Set(locked)
+		fxtronbridge.TokenBackedProm.WithLabelValues(report.Token).Set(backed)
+		fxtronbridge.TokenDiffRatioProm.WithLabelValues(report.Token).Set(report.DiffRatio)
+
+		alertName := notifier.AlertSolvencyMismatch + "_" + report.Token
+		if report.DiffRatio > threshold || report.DiffRatio < -threshold {
+			logger.Warnw("bridge token solvency mismatch", "token", report.Token, "denom", report.Denom,
+				"locked", report.Locked.String(), "supply", report.Supply.String(), "pending", report.Pending.String(), "diff", report.Diff.String())
+			notifier.Fire(alertName, "token %s (%s) locked %s, fx supply %s + pending %s, diff %s (%.4f%%)",
+				report.Symbol, report.Token, report.Locked, report.Supply, report.Pending, report.Diff, report.DiffRatio*100)
+		} else {
+			notifier.Resolve(alertName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package bridge
+
+import (
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+func TestReconcile(t *testing.T) {
+	const originatedToken = "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f"
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	bridgeState.tokens = []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr), newTestBridgeToken(t, originatedToken)}
+	bridgeState.originated[originatedToken] = true
+	bridgeState.balances[testTokenAddr] = big.NewInt(1000)
+	chain.denoms[testTokenAddr] = "tronusdt"
+	chain.supply = sdk.NewCoins(sdk.NewInt64Coin("tronusdt", 800))
+	// 180 + 10 are burned on fx core and still wait to be released on tron
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(3, 1000)}
+	chain.batches[0].Transactions[0].Token.Amount = sdk.NewInt(180)
+	chain.batches[0].Transactions[0].Fee.Amount = sdk.NewInt(10)
+
+	reports, err := fxBridge.Reconcile()
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.Equal(t, "tronusdt", reports[0].Denom)
+	require.Equal(t, "190", reports[0].Pending.String())
+	require.Equal(t, "10", reports[0].Diff.String())
+	require.InDelta(t, 10.0/990, reports[0].DiffRatio, 1e-9)
+	require.True(t, reports[1].Skipped)
+
+	require.NoError(t, fxBridge.checkSolvency(0.1))
+	delete(chain.denoms, testTokenAddr)
+	require.Error(t, fxBridge.checkSolvency(0.1))
+}
@@ -0,0 +1,157 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/gogo/protobuf/proto"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+const (
+	ReasonUnknownToken    = "unknown_token"
+	ReasonInactiveToken   = "inactive_token"
+	ReasonStaleNonce      = "stale_nonce"
+	ReasonInvalidAddress  = "invalid_address"
+	ReasonBatchTimeout    = "batch_timeout"
+	ReasonInvalidAmount   = "invalid_amount"
+	ReasonDuplicateMember = "duplicate_member"
+	ReasonInvalidPower    = "invalid_power"
+	ReasonSetMismatch     = "set_mismatch"
+)
+
+// VerifyError is returned when a request from fx core must not be signed, Reason is used as metric label.
+type VerifyError struct {
+	Reason  string
+	Message string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+func newVerifyError(reason, format string, args ...interface{}) *VerifyError {
+	return &VerifyError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+func isTronAddress(addr string) bool {
+	tronAddr, err := address.Base58ToAddress(addr)
+	if err != nil {
+		return false
+	}
+	return len(tronAddr) == address.AddressLength && tronAddr[0] == address.TronBytePrefix
+}
+
+// verifyBatch checks an outgoing batch against the bridge contract state, a *VerifyError means the batch
+// is invalid, any other error means the tron state could not be read.
+func verifyBatch(bridgeState BridgeState, bridgeAddr string, txBatch *crosschaintypes.OutgoingTxBatch) error {
+	if !isTronAddress(txBatch.TokenContract) {
+		return newVerifyError(ReasonInvalidAddress, "token contract %s", txBatch.TokenContract)
+	}
+	if !isTronAddress(txBatch.FeeReceive) {
+		return newVerifyError(ReasonInvalidAddress, "fee receive %s", txBatch.FeeReceive)
+	}
+	for _, transferTx := range txBatch.Transactions {
+		if !isTronAddress(transferTx.DestAddress) {
+			return newVerifyError(ReasonInvalidAddress, "tx %d dest address %s", transferTx.Id, transferTx.DestAddress)
+		}
+		if transferTx.Token.Amount.IsNil() || !transferTx.Token.Amount.IsPositive() {
+			return newVerifyError(ReasonInvalidAmount, "tx %d amount %s", transferTx.Id, transferTx.Token.Amount)
+		}
+		if transferTx.Fee.Amount.IsNil() || !transferTx.Fee.Amount.IsPositive() {
+			return newVerifyError(ReasonInvalidAmount, "tx %d fee %s", transferTx.Id, transferTx.Fee.Amount)
+		}
+	}
+
+	bridgeTokens, err := bridgeState.GetBridgeTokenList(bridgeAddr)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, bridgeToken := range bridgeTokens {
+		if contract.AddressToString(bridgeToken.Addr) == txBatch.TokenContract {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newVerifyError(ReasonUnknownToken, "token %s is not a bridge token", txBatch.TokenContract)
+	}
+	_, isActive, isExist, err := bridgeState.GetTokenStatus(bridgeAddr, txBatch.TokenContract)
+	if err != nil {
+		return err
+	}
+	if !isExist || !isActive {
+		return newVerifyError(ReasonInactiveToken, "token %s exist: %v, active: %v", txBatch.TokenContract, isExist, isActive)
+	}
+
+	lastBatchNonce, err := bridgeState.LastBatchNonce(bridgeAddr, txBatch.TokenContract)
+	if err != nil {
+		return err
+	}
+	if txBatch.BatchNonce <= lastBatchNonce {
+		return newVerifyError(ReasonStaleNonce, "batch nonce %d, contract last batch nonce %d", txBatch.BatchNonce, lastBatchNonce)
+	}
+
+	blockNumber, err := bridgeState.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	if txBatch.BatchTimeout <= blockNumber {
+		return newVerifyError(ReasonBatchTimeout, "batch timeout %d, tron block number %d", txBatch.BatchTimeout, blockNumber)
+	}
+	return nil
+}
+
+// verifyOracleSet checks a pending oracle set against the bridge contract and the oracle set stored by fx core,
+// a *VerifyError means the set is invalid, any other error means the state could not be read.
+func verifyOracleSet(bridgeState BridgeState, querier CrosschainQuerier, bridgeAddr string, oracleSet *crosschaintypes.OracleSet) error {
+	members := make(map[string]bool, len(oracleSet.Members))
+	var totalPower uint64
+	for _, member := range oracleSet.Members {
+		if !isTronAddress(member.ExternalAddress) {
+			return newVerifyError(ReasonInvalidAddress, "member %s", member.ExternalAddress)
+		}
+		if members[member.ExternalAddress] {
+			return newVerifyError(ReasonDuplicateMember, "member %s", member.ExternalAddress)
+		}
+		members[member.ExternalAddress] = true
+		if member.Power <= 0 {
+			return newVerifyError(ReasonInvalidPower, "member %s power %d", member.ExternalAddress, member.Power)
+		}
+		totalPower += member.Power
+	}
+	// fx core normalizes the member powers to uint32, the contract needs ThresholdVotePower to accept a signature set
+	if totalPower > math.MaxUint32 || totalPower < fxtronbridge.ThresholdVotePower {
+		return newVerifyError(ReasonInvalidPower, "total power %d, threshold %d, max %d", totalPower, uint64(fxtronbridge.ThresholdVotePower), uint64(math.MaxUint32))
+	}
+
+	lastOracleSetNonce, err := bridgeState.StateLastOracleSetNonce(bridgeAddr)
+	if err != nil {
+		return err
+	}
+	if oracleSet.Nonce <= lastOracleSetNonce {
+		return newVerifyError(ReasonStaleNonce, "oracle set nonce %d, contract last oracle set nonce %d", oracleSet.Nonce, lastOracleSetNonce)
+	}
+
+	storedSet, err := querier.OracleSetRequest(oracleSet.Nonce, fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	if storedSet == nil || !proto.Equal(storedSet, oracleSet) {
+		return newVerifyError(ReasonSetMismatch, "oracle set nonce %d differs from the stored oracle set request", oracleSet.Nonce)
+	}
+	currentSet, err := querier.CurrentOracleSet(fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	if currentSet != nil && currentSet.Nonce == oracleSet.Nonce && !proto.Equal(currentSet, oracleSet) {
+		return newVerifyError(ReasonSetMismatch, "oracle set nonce %d differs from the current oracle set", oracleSet.Nonce)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+const testTokenAddr = "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR"
+
+func newTestBridgeToken(t *testing.T, tokenAddr string) contract.FxBridgeToken {
+	addr, err := address.Base58ToAddress(tokenAddr)
+	require.NoError(t, err)
+	return contract.FxBridgeToken{Addr: ethcommon.BytesToAddress(addr.Bytes()[1:]), Symbol: "USDT", Decimals: 6}
+}
+
+func newTestTxBatch(batchNonce, batchTimeout uint64) *crosschaintypes.OutgoingTxBatch {
+	return &crosschaintypes.OutgoingTxBatch{
+		BatchNonce:   batchNonce,
+		BatchTimeout: batchTimeout,
+		Transactions: []*crosschaintypes.OutgoingTransferTx{
+			{
+				Id:          1,
+				Sender:      "fx1zgpzdf2uqla7hkx85wnn4p2r3duwqzd8xst6v2",
+				DestAddress: "TFysCB929XGezbnyumoFScyevjDggu3BPq",
+				Token:       crosschaintypes.ERC20Token{Contract: testTokenAddr, Amount: sdk.NewInt(2000)},
+				Fee:         crosschaintypes.ERC20Token{Contract: testTokenAddr, Amount: sdk.NewInt(10)},
+			},
+		},
+		TokenContract: testTokenAddr,
+		Block:         9,
+		FeeReceive:    "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f",
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		malleate func(state *memBridgeState, txBatch *crosschaintypes.OutgoingTxBatch)
+		reason   string
+	}{
+		{name: "valid", malleate: func(*memBridgeState, *crosschaintypes.OutgoingTxBatch) {}},
+		{name: "unknown token", reason: ReasonUnknownToken, malleate: func(state *memBridgeState, _ *crosschaintypes.OutgoingTxBatch) {
+			state.tokens = nil
+		}},
+		{name: "inactive token", reason: ReasonInactiveToken, malleate: func(state *memBridgeState, _ *crosschaintypes.OutgoingTxBatch) {
+			state.inactiveTokens[testTokenAddr] = true
+		}},
+		{name: "stale nonce", reason: ReasonStaleNonce, malleate: func(state *memBridgeState, _ *crosschaintypes.OutgoingTxBatch) {
+			state.lastBatchNonce[testTokenAddr] = 3
+		}},
+		{name: "invalid dest address", reason: ReasonInvalidAddress, malleate: func(_ *memBridgeState, txBatch *crosschaintypes.OutgoingTxBatch) {
+			txBatch.Transactions[0].DestAddress = "TFysCB929XGezbnyumoFScyevjDggu3BPQ"
+		}},
+		{name: "invalid fee receive", reason: ReasonInvalidAddress, malleate: func(_ *memBridgeState, txBatch *crosschaintypes.OutgoingTxBatch) {
+			txBatch.FeeReceive = "0x8a21bcef7269bd328bf843207bfe0d84dc3b68e9"
+		}},
+		{name: "batch timeout", reason: ReasonBatchTimeout, malleate: func(state *memBridgeState, _ *crosschaintypes.OutgoingTxBatch) {
+			state.blockNumber = 1000
+		}},
+		{name: "zero amount", reason: ReasonInvalidAmount, malleate: func(_ *memBridgeState, txBatch *crosschaintypes.OutgoingTxBatch) {
+			txBatch.Transactions[0].Token.Amount = sdk.ZeroInt()
+		}},
+		{name: "missing fee", reason: ReasonInvalidAmount, malleate: func(_ *memBridgeState, txBatch *crosschaintypes.OutgoingTxBatch) {
+			txBatch.Transactions[0].Fee.Amount = sdk.Int{}
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &memBridgeState{
+				blockNumber:    500,
+				tokens:         []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr)},
+				inactiveTokens: make(map[string]bool),
+				lastBatchNonce: map[string]uint64{testTokenAddr: 2},
+			}
+			txBatch := newTestTxBatch(3, 1000)
+			tc.malleate(state, txBatch)
+
+			err := verifyBatch(state, testBridgeAddr, txBatch)
+			if len(tc.reason) <= 0 {
+				require.NoError(t, err)
+				return
+			}
+			var verifyErr *VerifyError
+			require.True(t, errors.As(err, &verifyErr), err)
+			require.Equal(t, tc.reason, verifyErr.Reason)
+		})
+	}
+}
+
+func TestSingerSkipsInvalidBatch(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	bridgeState.blockNumber = 500
+	bridgeState.tokens = []contract.FxBridgeToken{newTestBridgeToken(t, testTokenAddr)}
+	bridgeState.lastBatchNonce[testTokenAddr] = 3
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(3, 1000)}
+
+	singer, err := NewSinger(fxBridge, "FX")
+	require.NoError(t, err)
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.built, 0)
+
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(4, 1000)}
+	require.NoError(t, singer.confirm())
+	require.Len(t, chain.sent, 1)
+	require.Equal(t, uint64(4), chain.sent[0][0].(*crosschaintypes.MsgConfirmBatch).Nonce)
+}
+
+func TestVerifyOracleSet(t *testing.T) {
+	const member1, member2 = "TFysCB929XGezbnyumoFScyevjDggu3BPq", "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f"
+	newOracleSet := func() *crosschaintypes.OracleSet {
+		return &crosschaintypes.OracleSet{
+			Nonce: 3,
+			Members: crosschaintypes.BridgeValidators{
+				{Power: 2147483647, ExternalAddress: member1},
+				{Power: 2147483648, ExternalAddress: member2},
+			},
+			Height: 100,
+		}
+	}
+	testCases := []struct {
+		name     string
+		malleate func(state *memBridgeState, chain *memChain, oracleSet *crosschaintypes.OracleSet)
+		reason   string
+	}{
+		{name: "valid", malleate: func(*memBridgeState, *memChain, *crosschaintypes.OracleSet) {}},
+		{name: "stale nonce", reason: ReasonStaleNonce, malleate: func(state *memBridgeState, _ *memChain, _ *crosschaintypes.OracleSet) {
+			state.lastOracleSetNonce = 3
+		}},
+		{name: "invalid member", reason: ReasonInvalidAddress, malleate: func(_ *memBridgeState, _ *memChain, oracleSet *crosschaintypes.OracleSet) {
+			oracleSet.Members[0].ExternalAddress = "fx1zgpzdf2uqla7hkx85wnn4p2r3duwqzd8xst6v2"
+		}},
+		{name: "duplicate member", reason: ReasonDuplicateMember, malleate: func(_ *memBridgeState, _ *memChain, oracleSet *crosschaintypes.OracleSet) {
+			oracleSet.Members[1].ExternalAddress = member1
+		}},
+		{name: "power overflow", reason: ReasonInvalidPower, malleate: func(_ *memBridgeState, _ *memChain, oracleSet *crosschaintypes.OracleSet) {
+			oracleSet.Members[1].Power++
+		}},
+		{name: "power below threshold", reason: ReasonInvalidPower, malleate: func(_ *memBridgeState, _ *memChain, oracleSet *crosschaintypes.OracleSet) {
+			oracleSet.Members[0].Power, oracleSet.Members[1].Power = 100, 100
+		}},
+		{name: "differs from stored request", reason: ReasonSetMismatch, malleate: func(_ *memBridgeState, chain *memChain, _ *crosschaintypes.OracleSet) {
+			chain.oracleSets[0].Height = 101
+		}},
+		{name: "differs from current set", reason: ReasonSetMismatch, malleate: func(_ *memBridgeState, chain *memChain, _ *crosschaintypes.OracleSet) {
+			chain.currentSet = newOracleSet()
+			chain.currentSet.Members[0].Power, chain.currentSet.Members[1].Power = 2147483648, 2147483647
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &memBridgeState{lastOracleSetNonce: 2}
+			chain := &memChain{oracleSets: []*crosschaintypes.OracleSet{newOracleSet()}}
+			oracleSet := newOracleSet()
+			tc.malleate(state, chain, oracleSet)
+
+			err := verifyOracleSet(state, chain, testBridgeAddr, oracleSet)
+			if len(tc.reason) <= 0 {
+				require.NoError(t, err)
+				return
+			}
+			var verifyErr *VerifyError
+			require.True(t, errors.As(err, &verifyErr), err)
+			require.Equal(t, tc.reason, verifyErr.Reason)
+		})
+	}
+}
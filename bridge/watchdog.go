@@ -0,0 +1,104 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+// BatchWatchdog tracks how close every outgoing batch is to its timeout and how much power already confirmed it.
+type BatchWatchdog struct {
+	*FxTronBridge
+	warnBlocks uint64
+	// alerts are the batch alerts fired by the last check, they are resolved once the batch leaves fx core
+	alerts map[string]bool
+}
+
+func NewBatchWatchdog(fxBridge *FxTronBridge, warnBlocks uint64) *BatchWatchdog {
+	return &BatchWatchdog{FxTronBridge: fxBridge, warnBlocks: warnBlocks, alerts: make(map[string]bool)}
+}
+
+func (w *BatchWatchdog) check() error {
+	blockNumber, err := w.BridgeState.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	batches, err := w.Querier.OutgoingTxBatches(fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	// the contract accepts a batch signed by its own oracle set, which may lag behind the fx core one
+	oracleSetNonce, err := w.BridgeState.StateLastOracleSetNonce(w.BridgeAddr)
+	if err != nil {
+		return err
+	}
+	oracleSet, err := w.Querier.OracleSetRequest(oracleSetNonce, fxtronbridge.Tron)
+	if err != nil {
+		return err
+	}
+	if oracleSet == nil {
+		return fmt.Errorf("fx core oracle set request not found nonce: %d", oracleSetNonce)
+	}
+
+	fxtronbridge.BatchTimeoutBlocksProm.Reset()
+	fxtronbridge.BatchConfirmPowerProm.Reset()
+	alerts := make(map[string]bool)
+	for _, batch := range batches {
+		confirms, err := w.Querier.BatchConfirms(batch.BatchNonce, batch.TokenContract, fxtronbridge.Tron)
+		if err != nil {
+			return err
+		}
+		var remaining uint64
+		if batch.BatchTimeout > blockNumber {
+			remaining = batch.BatchTimeout - blockNumber
+		}
+		power, missing := batchConfirmPower(oracleSet, confirms)
+		nonce := strconv.FormatUint(batch.BatchNonce, 10)
+		fxtronbridge.BatchTimeoutBlocksProm.WithLabelValues(batch.TokenContract, nonce).Set(float64(remaining))
+		fxtronbridge.BatchConfirmPowerProm.WithLabelValues(batch.TokenContract, nonce).Set(float64(power))
+		logger.Debugw("batch timeout watchdog", "token", batch.TokenContract, "batch_nonce", batch.BatchNonce,
+			"batch_timeout", batch.BatchTimeout, "block_number", blockNumber, "confirm_power", power)
+
+		alertName := fmt.Sprintf("%s_%s_%d", notifier.AlertBatchTimeout, batch.TokenContract, batch.BatchNonce)
+		if remaining <= w.warnBlocks && power < fxtronbridge.ThresholdVotePower {
+			alerts[alertName] = true
+			logger.Warnw("batch close to timeout", "token", batch.TokenContract, "batch_nonce", batch.BatchNonce,
+				"remaining_blocks", remaining, "confirm_power", power, "missing_oracles", missing)
+			notifier.Fire(alertName, "batch %s nonce %d times out in %d tron blocks with power %d of %d, missing oracles: %s",
+				batch.TokenContract, batch.BatchNonce, remaining, power, uint64(fxtronbridge.ThresholdVotePower), strings.Join(missing, ", "))
+		}
+	}
+	for alertName := range w.alerts {
+		if !alerts[alertName] {
+			notifier.Resolve(alertName)
+		}
+	}
+	w.alerts = alerts
+	return nil
+}
+
+// batchConfirmPower returns the power of the oracle set members that confirmed a batch,
+// and the external address of the members that did not.
+func batchConfirmPower(oracleSet *crosschaintypes.OracleSet, confirms []*crosschaintypes.MsgConfirmBatch) (uint64, []string) {
+	confirmed := make(map[string]bool, len(confirms))
+	for _, confirm := range confirms {
+		confirmed[confirm.ExternalAddress] = true
+	}
+	var power uint64
+	missing := make([]string, 0)
+	for _, member := range oracleSet.Members {
+		if confirmed[member.ExternalAddress] {
+			power += member.Power
+		} else {
+			missing = append(missing, member.ExternalAddress)
+		}
+	}
+	return power, missing
+}
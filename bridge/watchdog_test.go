@@ -0,0 +1,45 @@
+package bridge
+
+import (
+	"testing"
+
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
+)
+
+func TestBatchConfirmPower(t *testing.T) {
+	oracleSet := &crosschaintypes.OracleSet{Members: crosschaintypes.BridgeValidators{
+		{Power: 100, ExternalAddress: "a"},
+		{Power: 200, ExternalAddress: "b"},
+		{Power: 700, ExternalAddress: "c"},
+	}}
+	confirms := []*crosschaintypes.MsgConfirmBatch{{ExternalAddress: "b"}, {ExternalAddress: "d"}}
+	power, missing := batchConfirmPower(oracleSet, confirms)
+	require.Equal(t, uint64(200), power)
+	require.Equal(t, []string{"a", "c"}, missing)
+}
+
+func TestBatchWatchdogCheck(t *testing.T) {
+	fxBridge, _, bridgeState, chain := newMemFxTronBridgeWithState(t)
+	bridgeState.blockNumber = 1000
+	bridgeState.lastOracleSetNonce = 2
+	chain.oracleSets = []*crosschaintypes.OracleSet{{Nonce: 2, Members: crosschaintypes.BridgeValidators{
+		{Power: 100, ExternalAddress: "a"},
+	}}}
+	chain.batches = []*crosschaintypes.OutgoingTxBatch{newTestTxBatch(3, 1100)}
+	alertName := notifier.AlertBatchTimeout + "_" + testTokenAddr + "_3"
+
+	watchdog := NewBatchWatchdog(fxBridge, 200)
+	require.NoError(t, watchdog.check())
+	require.True(t, watchdog.alerts[alertName])
+
+	// the batch is submitted to tron and removed from fx core
+	chain.batches = nil
+	require.NoError(t, watchdog.check())
+	require.Empty(t, watchdog.alerts)
+
+	bridgeState.lastOracleSetNonce = 5
+	require.Error(t, watchdog.check())
+}
@@ -0,0 +1,374 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+)
+
+const (
+	// maxFilterLogsBlocks bounds the blocks FilterLogs reads, every block is one GetTransactionInfoByBlockNum call
+	maxFilterLogsBlocks = 10000
+	tronBlockInterval   = 3 * time.Second
+)
+
+var _ bind.ContractBackend = (*ContractBackend)(nil)
+var _ bind.DeployBackend = (*ContractBackend)(nil)
+
+// ContractBackend lets the generated go-ethereum bindings run against tron. The 20 bytes addresses of the
+// bindings are tron addresses without the 0x41 prefix. Transactions are signed by the tron keys registered
+// with NewTransactOpts, the ethereum transaction only carries the call to the backend.
+type ContractBackend struct {
+	client *TronClient
+
+	mu      sync.Mutex
+	keys    map[ethcommon.Address]*ecdsa.PrivateKey
+	nonces  map[ethcommon.Address]uint64
+	txIds   map[ethcommon.Hash][]byte
+	ethSign types.Signer
+}
+
+func NewContractBackend(client *TronClient) *ContractBackend {
+	return &ContractBackend{
+		client:  client,
+		keys:    make(map[ethcommon.Address]*ecdsa.PrivateKey),
+		nonces:  make(map[ethcommon.Address]uint64),
+		txIds:   make(map[ethcommon.Hash][]byte),
+		ethSign: types.HomesteadSigner{},
+	}
+}
+
+// TronAddress converts a binding address to its base58 tron address.
+func TronAddress(addr ethcommon.Address) address.Address {
+	return append([]byte{address.TronBytePrefix}, addr.Bytes()...)
+}
+
+// NewTransactOpts returns the transact options of a binding method sent from the tron address of privKey.
+func (b *ContractBackend) NewTransactOpts(privKey *ecdsa.PrivateKey) *bind.TransactOpts {
+	from := crypto.PubkeyToAddress(privKey.PublicKey)
+	b.mu.Lock()
+	b.keys[from] = privKey
+	b.mu.Unlock()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(signer ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if signer != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			// the ethereum signature only tells SendTransaction which tron key signs the call
+			return types.SignTx(tx, b.ethSign, privKey)
+		},
+		Context: context.Background(),
+	}
+}
+
+// TronTxId returns the id of the tron transaction sent for a binding transaction.
+func (b *ContractBackend) TronTxId(txHash ethcommon.Hash) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	txId, ok := b.txIds[txHash]
+	return txId, ok
+}
+
+func (b *ContractBackend) CodeAt(ctx context.Context, contractAddr ethcommon.Address, _ *big.Int) ([]byte, error) {
+	smartContract, err := b.client.Client.GetContract(ctx, &api.BytesMessage{Value: TronAddress(contractAddr).Bytes()})
+	if err != nil {
+		return nil, err
+	}
+	return smartContract.GetBytecode(), nil
+}
+
+// CallContract runs a constant call, tron only serves the latest state so blockNumber is ignored.
+func (b *ContractBackend) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if call.To == nil {
+		return nil, fmt.Errorf("constant call without contract address")
+	}
+	return b.client.CallContract(TronAddress(call.From).String(), TronAddress(*call.To).String(), call.Data)
+}
+
+// HeaderByNumber returns a header without base fee so that the bindings build legacy transactions.
+func (b *ContractBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil {
+		return &types.Header{Number: new(big.Int).Set(number)}, nil
+	}
+	blockNumber, err := b.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(blockNumber)}, nil
+}
+
+func (b *ContractBackend) PendingCodeAt(ctx context.Context, account ethcommon.Address) ([]byte, error) {
+	return b.CodeAt(ctx, account, nil)
+}
+
+// PendingNonceAt counts the binding transactions of account, tron has no nonce but the counter keeps
+// the hashes of identical calls apart in TronTxId.
+func (b *ContractBackend) PendingNonceAt(_ context.Context, account ethcommon.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nonce := b.nonces[account]
+	b.nonces[account]++
+	return nonce, nil
+}
+
+// SuggestGasPrice is the energy price in sun.
+func (b *ContractBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.client.SuggestGasPrice(ctx)
+}
+
+func (b *ContractBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+// EstimateGas is the energy used by the call.
+func (b *ContractBackend) EstimateGas(_ context.Context, call ethereum.CallMsg) (uint64, error) {
+	if call.To == nil {
+		return 0, fmt.Errorf("can not estimate a contract creation, use DeployBridgeContract")
+	}
+	return b.client.EstimateGas(TronAddress(call.From).Bytes(), TronAddress(*call.To).Bytes(), call.Data)
+}
+
+// SendTransaction triggers the call of tx from its sender, signs it with the registered tron key and
+// broadcasts it. The fee limit is the energy of tx at its gas price.
+func (b *ContractBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	if tx.To() == nil {
+		return fmt.Errorf("contract creation is not supported, use DeployBridgeContract")
+	}
+	from, err := types.Sender(b.ethSign, tx)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	privKey, ok := b.keys[from]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no tron key for %s", TronAddress(from).String())
+	}
+	tronTx, err := b.client.TriggerContract(&troncontract.TriggerSmartContract{
+		OwnerAddress:    TronAddress(from).Bytes(),
+		ContractAddress: TronAddress(*tx.To()).Bytes(),
+		Data:            tx.Data(),
+		CallValue:       tx.Value().Int64(),
+	}, GetLimit(tx.GasPrice(), tx.Gas()))
+	if err != nil {
+		return err
+	}
+	if err = SignTransaction(tronTx.Transaction, privKey); err != nil {
+		return err
+	}
+	if _, err = b.client.BroadcastTx(tronTx); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.txIds[tx.Hash()] = tronTx.Txid
+	b.mu.Unlock()
+	return nil
+}
+
+// TransactionReceipt returns the receipt of the tron transaction sent for the binding transaction txHash,
+// ethereum.NotFound until it is in a block.
+func (b *ContractBackend) TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	txId, ok := b.TronTxId(txHash)
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	info, err := b.client.Client.GetTransactionInfoById(ctx, &api.BytesMessage{Value: txId})
+	if err != nil {
+		return nil, err
+	}
+	if len(info.GetId()) <= 0 {
+		return nil, ethereum.NotFound
+	}
+	receipt := &types.Receipt{
+		Type:        types.LegacyTxType,
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		GasUsed:     uint64(info.GetReceipt().GetEnergyUsageTotal()),
+		BlockNumber: big.NewInt(info.GetBlockNumber()),
+	}
+	if info.GetReceipt().GetResult() != core.Transaction_Result_SUCCESS {
+		receipt.Status = types.ReceiptStatusFailed
+	}
+	if len(info.GetContractAddress()) > 0 {
+		receipt.ContractAddress = ethcommon.BytesToAddress(info.GetContractAddress())
+	}
+	for i, log := range info.GetLog() {
+		receipt.Logs = append(receipt.Logs, tronLog(log, info, 0, uint(i)))
+	}
+	return receipt, nil
+}
+
+// FilterLogs scans the transaction infos of every block in the range, a nil ToBlock is the latest block.
+// FromBlock is required, scanning tron from the genesis block is never what the caller wants.
+func (b *ContractBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if query.BlockHash != nil {
+		return nil, errors.New("filter by block hash is not supported")
+	}
+	if query.FromBlock == nil {
+		return nil, errors.New("filter from block is required")
+	}
+	toBlock := uint64(0)
+	if query.ToBlock != nil {
+		toBlock = query.ToBlock.Uint64()
+	} else {
+		blockNumber, err := b.client.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		toBlock = blockNumber
+	}
+	fromBlock := query.FromBlock.Uint64()
+	if fromBlock > toBlock {
+		return nil, nil
+	}
+	if toBlock-fromBlock >= maxFilterLogsBlocks {
+		return nil, fmt.Errorf("filter range %d-%d is longer than %d blocks", fromBlock, toBlock, maxFilterLogsBlocks)
+	}
+	logs := make([]types.Log, 0)
+	for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
+		blockLogs, err := b.blockLogs(blockNumber, query)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, blockLogs...)
+	}
+	return logs, nil
+}
+
+// SubscribeFilterLogs polls every new block for the logs of query, starting at FromBlock or the next block.
+func (b *ContractBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if query.BlockHash != nil {
+		return nil, errors.New("filter by block hash is not supported")
+	}
+	latest, err := b.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	next := latest + 1
+	if query.FromBlock != nil {
+		next = query.FromBlock.Uint64()
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(tronBlockInterval)
+		defer ticker.Stop()
+		for {
+			latest, err = b.client.BlockNumber(ctx)
+			if err != nil {
+				return err
+			}
+			for ; next <= latest; next++ {
+				if query.ToBlock != nil && next > query.ToBlock.Uint64() {
+					return nil
+				}
+				logs, err := b.blockLogs(next, query)
+				if err != nil {
+					return err
+				}
+				for _, log := range logs {
+					select {
+					case ch <- log:
+					case <-quit:
+						return nil
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}), nil
+}
+
+func (b *ContractBackend) blockLogs(blockNumber uint64, query ethereum.FilterQuery) ([]types.Log, error) {
+	blockInfo, err := b.client.GetBlockInfoByNum(int64(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]types.Log, 0)
+	logIndex := uint(0)
+	for txIndex, info := range blockInfo.GetTransactionInfo() {
+		for _, log := range info.GetLog() {
+			index := logIndex
+			logIndex++
+			if info.GetReceipt().GetResult() != core.Transaction_Result_SUCCESS {
+				continue
+			}
+			ethLog := tronLog(log, info, uint(txIndex), index)
+			if matchLog(ethLog, query) {
+				logs = append(logs, *ethLog)
+			}
+		}
+	}
+	return logs, nil
+}
+
+func tronLog(log *core.TransactionInfo_Log, info *core.TransactionInfo, txIndex, index uint) *types.Log {
+	topics := make([]ethcommon.Hash, 0, len(log.GetTopics()))
+	for _, topic := range log.GetTopics() {
+		topics = append(topics, ethcommon.BytesToHash(topic))
+	}
+	return &types.Log{
+		Address:     ethcommon.BytesToAddress(log.GetAddress()),
+		Topics:      topics,
+		Data:        log.GetData(),
+		BlockNumber: uint64(info.GetBlockNumber()),
+		TxHash:      ethcommon.BytesToHash(info.GetId()),
+		TxIndex:     txIndex,
+		Index:       index,
+	}
+}
+
+// matchLog applies the address and topic filters of query, an empty topic position matches any topic.
+func matchLog(log *types.Log, query ethereum.FilterQuery) bool {
+	if len(query.Addresses) > 0 {
+		found := false
+		for _, addr := range query.Addresses {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(query.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, topics := range query.Topics {
+		if len(topics) <= 0 {
+			continue
+		}
+		found := false
+		for _, topic := range topics {
+			if topic == log.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
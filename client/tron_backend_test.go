@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func TestContractBackendCallAndTransact(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	backend := NewContractBackend(cli)
+	bridgeAddr, err := contract.StringToAddress(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, testBridgeAddr, TronAddress(bridgeAddr).String())
+	fxBridge, err := contract.NewFxBridgeTron(bridgeAddr, backend)
+	require.NoError(t, err)
+
+	var bridgeId [32]byte
+	copy(bridgeId[:], "tron")
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_fxBridgeId", bridgeId))
+	stateBridgeId, err := fxBridge.StateFxBridgeId(&bind.CallOpts{})
+	require.NoError(t, err)
+	require.Equal(t, bridgeId, stateBridgeId)
+
+	unknown := ethcommon.BytesToAddress([]byte("unknown"))
+	_, err = contract.NewFxBridgeTronCaller(unknown, backend)
+	require.NoError(t, err)
+	_, err = backend.PendingCodeAt(context.Background(), unknown)
+	require.NoError(t, err)
+	unknownBridge, err := contract.NewFxBridgeTron(unknown, backend)
+	require.NoError(t, err)
+
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts := backend.NewTransactOpts(privKey)
+	_, err = unknownBridge.Pause(opts)
+	require.ErrorIs(t, err, bind.ErrNoCode)
+
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("pause()")))
+	tx, err := fxBridge.Pause(opts)
+	require.NoError(t, err)
+	txId, ok := backend.TronTxId(tx.Hash())
+	require.True(t, ok)
+
+	transactions := fakeTron.Transactions()
+	require.Len(t, transactions, 1)
+	trigger := new(troncontract.TriggerSmartContract)
+	require.NoError(t, transactions[0].RawData.Contract[0].Parameter.UnmarshalTo(trigger))
+	require.Equal(t, address.PubkeyToAddress(privKey.PublicKey).Bytes(), []byte(address.Address(trigger.OwnerAddress)))
+	require.Equal(t, testutil.Selector("pause()"), trigger.Data)
+	require.Equal(t, GetLimit(big.NewInt(420), 0), transactions[0].RawData.FeeLimit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Equal(t, tx.Hash(), receipt.TxHash)
+	require.NotEmpty(t, txId)
+
+	// the same call again is a new binding transaction
+	again, err := fxBridge.Pause(opts)
+	require.NoError(t, err)
+	require.NotEqual(t, tx.Hash(), again.Hash())
+	againTxId, ok := backend.TronTxId(again.Hash())
+	require.True(t, ok)
+	require.NotEmpty(t, againTxId)
+	txId, ok = backend.TronTxId(tx.Hash())
+	require.True(t, ok)
+	require.NotEmpty(t, txId)
+
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts.From = crypto.PubkeyToAddress(otherKey.PublicKey)
+	_, err = fxBridge.Pause(opts)
+	require.ErrorIs(t, err, bind.ErrNotAuthorized)
+}
+
+func TestContractBackendFilterLogs(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	backend := NewContractBackend(cli)
+	bridgeAddr, err := contract.StringToAddress(testBridgeAddr)
+	require.NoError(t, err)
+	fxBridge, err := contract.NewFxBridgeTron(bridgeAddr, backend)
+	require.NoError(t, err)
+
+	token := ethcommon.BytesToAddress([]byte("token"))
+	otherToken := ethcommon.BytesToAddress([]byte("other token"))
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	var destination, targetIBC [32]byte
+	require.NoError(t, fakeTron.AddEvent(60, testBridgeAddr, crypto.Keccak256([]byte("tx1")), "SendToFxEvent",
+		token, sender, destination, targetIBC, big.NewInt(100), big.NewInt(1)))
+	require.NoError(t, fakeTron.AddEvent(61, testBridgeAddr, crypto.Keccak256([]byte("tx2")), "AddBridgeTokenEvent",
+		token, "Token", "TKN", uint8(6), big.NewInt(2), targetIBC))
+	require.NoError(t, fakeTron.AddEvent(62, testBridgeAddr, crypto.Keccak256([]byte("tx3")), "SendToFxEvent",
+		otherToken, sender, destination, targetIBC, big.NewInt(300), big.NewInt(3)))
+	require.NoError(t, fakeTron.AddEvent(62, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", crypto.Keccak256([]byte("tx4")), "SendToFxEvent",
+		token, sender, destination, targetIBC, big.NewInt(400), big.NewInt(4)))
+	fakeTron.SetBlockNumber(65)
+
+	iterator, err := fxBridge.FilterSendToFxEvent(&bind.FilterOpts{Start: 50}, nil, nil, nil)
+	require.NoError(t, err)
+	nonces := make([]uint64, 0)
+	for iterator.Next() {
+		nonces = append(nonces, iterator.Event.EventNonce.Uint64())
+		require.Equal(t, sender, iterator.Event.Sender)
+	}
+	require.NoError(t, iterator.Error())
+	require.Equal(t, []uint64{1, 3}, nonces)
+
+	end := uint64(61)
+	iterator, err = fxBridge.FilterSendToFxEvent(&bind.FilterOpts{Start: 50, End: &end}, []ethcommon.Address{otherToken}, nil, nil)
+	require.NoError(t, err)
+	require.False(t, iterator.Next())
+
+	logs, err := backend.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(62), ToBlock: big.NewInt(62)})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, uint(1), logs[1].Index)
+	require.Equal(t, uint64(62), logs[1].BlockNumber)
+	_, err = backend.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(maxFilterLogsBlocks)})
+	require.Error(t, err)
+	_, err = backend.FilterLogs(context.Background(), ethereum.FilterQuery{ToBlock: big.NewInt(62)})
+	require.Error(t, err)
+
+	sink := make(chan *contract.FxBridgeTronSendToFxEvent, 1)
+	start := uint64(62)
+	subscription, err := fxBridge.WatchSendToFxEvent(&bind.WatchOpts{Start: &start}, sink, nil, nil, nil)
+	require.NoError(t, err)
+	defer subscription.Unsubscribe()
+	select {
+	case event := <-sink:
+		require.Equal(t, uint64(3), event.EventNonce.Uint64())
+	case err = <-subscription.Err():
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event from the subscription")
+	}
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
@@ -13,59 +14,134 @@ import (
 	sdkCommon "github.com/fbsobreira/gotron-sdk/pkg/common"
 	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
 	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
 
 	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
 )
 
+const testBridgeAddr = "TVSMxNVuhzHTCvcnPzFmyAn2B2iDQjdgQh"
+
 func TestStateLastOracleSetNonce(t *testing.T) {
-	tronClient := NewTestTronClient(t)
-	nonce, err := tronClient.StateLastOracleSetNonce("TVSMxNVuhzHTCvcnPzFmyAn2B2iDQjdgQh")
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log("nonce:", nonce)
+	tronClient, fakeTron := NewTestTronClient(t)
+	result, err := fxBridgeAbi.Methods["state_lastOracleSetNonce"].Outputs.Pack(big.NewInt(5))
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("state_lastOracleSetNonce()"), result))
+
+	nonce, err := tronClient.StateLastOracleSetNonce(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
 }
 
 func TestStateFxBridgeId(t *testing.T) {
-	tronClient := NewTestTronClient(t)
-	fxBridgeId, err := tronClient.StateFxBridgeId("TVSMxNVuhzHTCvcnPzFmyAn2B2iDQjdgQh")
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log("fxBridgeId:", fxBridgeId)
+	tronClient, fakeTron := NewTestTronClient(t)
+	var bridgeId [32]byte
+	copy(bridgeId[:], "tron")
+	result, err := fxBridgeAbi.Methods["state_fxBridgeId"].Outputs.Pack(bridgeId)
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("state_fxBridgeId()"), result))
+
+	fxBridgeId, err := tronClient.StateFxBridgeId(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, "tron", fxBridgeId)
 }
 
 func TestStateLastOracleSetHeight(t *testing.T) {
-	tronClient := NewTestTronClient(t)
-	lastOracleSetHeight, err := tronClient.StateLastOracleSetHeight("TVSMxNVuhzHTCvcnPzFmyAn2B2iDQjdgQh")
-	if err != nil {
-		t.Fatal(err)
+	tronClient, fakeTron := NewTestTronClient(t)
+	result, err := fxBridgeAbi.Methods["state_laseOracleSetHeight"].Outputs.Pack(big.NewInt(1000))
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("state_laseOracleSetHeight()"), result))
+
+	lastOracleSetHeight, err := tronClient.StateLastOracleSetHeight(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), lastOracleSetHeight)
+}
+
+func TestStateLastOracleSetCheckpoint(t *testing.T) {
+	tronClient, fakeTron := NewTestTronClient(t)
+	checkpoint := crypto.Keccak256Hash([]byte("checkpoint"))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_lastOracleSetCheckpoint", checkpoint))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_powerThreshold", big.NewInt(1870887754)))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "makeCheckpoint", checkpoint))
+
+	lastCheckpoint, err := tronClient.StateLastOracleSetCheckpoint(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, [32]byte(checkpoint), lastCheckpoint)
+
+	powerThreshold, err := tronClient.StatePowerThreshold(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1870887754), powerThreshold)
+
+	oracleSet := crosschaintypes.OracleSet{
+		Nonce:   1,
+		Members: crosschaintypes.BridgeValidators{{Power: 100, ExternalAddress: "TFysCB929XGezbnyumoFScyevjDggu3BPq"}},
 	}
-	t.Log("lastOracleSetHeight:", lastOracleSetHeight)
+	madeCheckpoint, err := tronClient.MakeCheckpoint(testBridgeAddr, oracleSet, "tron")
+	require.NoError(t, err)
+	require.Equal(t, [32]byte(checkpoint), madeCheckpoint)
 }
 
 func TestGetTokenStatus(t *testing.T) {
-	tronClient := NewTestTronClient(t)
-	a, b, c, err := tronClient.GetTokenStatus("TAXdkUAde6ztmJyQqANL4jwDYab8D9shQs", "TLBaRhANQoJFTqre9Nf1mjuwNWjCJeYqUL")
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log("tokenStatus:", a, b, c)
+	tronClient, fakeTron := NewTestTronClient(t)
+	result, err := fxBridgeAbi.Methods["tokenStatus"].Outputs.Pack(false, true, true)
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("tokenStatus(address)"), result))
+
+	isOriginated, isActive, isExist, err := tronClient.GetTokenStatus(testBridgeAddr, "TLBaRhANQoJFTqre9Nf1mjuwNWjCJeYqUL")
+	require.NoError(t, err)
+	require.False(t, isOriginated)
+	require.True(t, isActive)
+	require.True(t, isExist)
 }
 
 func TestGetBridgeTokenList(t *testing.T) {
-	tronClient := NewTestTronClient(t)
-	bridgeTokenList, err := tronClient.GetBridgeTokenList("TAXdkUAde6ztmJyQqANL4jwDYab8D9shQs")
+	tronClient, fakeTron := NewTestTronClient(t)
+	tokenAddr, err := address.Base58ToAddress("TLBaRhANQoJFTqre9Nf1mjuwNWjCJeYqUL")
+	require.NoError(t, err)
+	tokens := []contract.FxBridgeToken{
+		{Addr: ethCommon.BytesToAddress(tokenAddr.Bytes()[1:]), Name: "Tether USD", Symbol: "USDT", Decimals: 6},
+	}
+	result, err := fxBridgeAbi.Methods["getBridgeTokenList"].Outputs.Pack(tokens)
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("getBridgeTokenList()"), result))
+
+	bridgeTokenList, err := tronClient.GetBridgeTokenList(testBridgeAddr)
+	require.NoError(t, err)
+	require.Equal(t, tokens, bridgeTokenList)
+}
+
+func TestQueryBlockEvent(t *testing.T) {
+	tronClient, fakeTron := NewTestTronClient(t)
+	fakeTron.SetBlockNumber(100)
+	tokenAddr := ethCommon.HexToAddress("0x1")
+	var destination [32]byte
+	err := fakeTron.AddEvent(60, testBridgeAddr, crypto.Keccak256([]byte("tx1")), "SendToFxEvent",
+		tokenAddr, ethCommon.HexToAddress("0x2"), destination, [32]byte{}, big.NewInt(100), big.NewInt(1))
+	require.NoError(t, err)
+
+	events, err := tronClient.QueryBlockEvent(testBridgeAddr, 60)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(1), events[0].GetEventNonce())
+
+	events, err = tronClient.QueryBlockEvent(testBridgeAddr, 61)
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+}
+
+// NewTestTronClient returns a client connected to an in-process fake tron node.
+func NewTestTronClient(t *testing.T) (*TronClient, *testutil.FakeTron) {
+	fakeTron := testutil.NewFakeTron(t)
+	client, err := NewTronGrpcClient(fakeTron.URL)
 	if err != nil {
 		t.Fatal(err)
 	}
-	for _, token := range bridgeTokenList {
-		t.Log(token)
-	}
+	return client, fakeTron
 }
 
-func NewTestTronClient(t *testing.T) *TronClient {
-	client, err := NewTronGrpcClient("http://127.0.0.1:50051") // testnet
+// NewLiveTronClient returns a client connected to the node in TRON_TEST_GRPC_URL.
+func NewLiveTronClient(t *testing.T) *TronClient {
+	client, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -73,7 +149,7 @@ func NewTestTronClient(t *testing.T) *TronClient {
 }
 
 func Test_Fee(t *testing.T) {
-	tronClient := NewTestTronClient(t)
+	tronClient := NewLiveTronClient(t)
 	contractAddress := "TKEdTmLSocskqBUFL2fBHSUixhZbo9RG55"
 
 	var i int64 = 20941200
@@ -110,7 +186,7 @@ func Test_Fee(t *testing.T) {
 }
 
 func Test_GetBlockInfoByNum(t *testing.T) {
-	tronClient := NewTestTronClient(t)
+	tronClient := NewLiveTronClient(t)
 
 	blockInfo, err := tronClient.GetBlockInfoByNum(20874558)
 	if err != nil {
@@ -186,6 +262,9 @@ func TestEncodeConfirmBatchHash(t *testing.T) {
 }
 
 func TestPrivateKeyToAddress(t *testing.T) {
+	if len(testTronPrivKey) <= 0 {
+		t.Skip("testTronPrivKey not set")
+	}
 	tronPrivateKey, err := crypto.HexToECDSA(testTronPrivKey)
 	if err != nil {
 		t.Fatal(err)
@@ -12,33 +12,36 @@ import (
 	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
 	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
 	"github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/google"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
 )
 
 type TronClient struct {
 	*client.GrpcClient
+	contractState
 }
 
 func NewTronGrpcClient(grpcUrl string) (*TronClient, error) {
+	return NewTronGrpcClientWithOptions(grpcUrl, fxtronbridge.GrpcOptions{})
+}
+
+// NewTronGrpcClientWithOptions connects to a tron node with the tls and header options, e.g. the TronGrid api key.
+func NewTronGrpcClientWithOptions(grpcUrl string, options fxtronbridge.GrpcOptions) (*TronClient, error) {
 	parseUrl, err := url.Parse(grpcUrl)
 	if err != nil {
 		return nil, err
 	}
-	host := parseUrl.Host
-	cli := client.NewGrpcClient(host)
-
-	var opts []grpc.DialOption
-	if parseUrl.Scheme == "https" {
-		opts = append(opts, grpc.WithCredentialsBundle(google.NewDefaultCredentials()))
-	} else {
-		opts = append(opts, grpc.WithInsecure())
+	cli := client.NewGrpcClient(parseUrl.Host)
+	opts, err := options.DialOptions(parseUrl, "tron")
+	if err != nil {
+		return nil, err
 	}
-
 	if err := cli.Start(opts...); err != nil {
 		return nil, err
 	}
-	return &TronClient{GrpcClient: cli}, nil
+	tronClient := &TronClient{GrpcClient: cli}
+	tronClient.contractState = contractState{caller: NewContractBackend(tronClient)}
+	return tronClient, nil
 }
 
 func (c *TronClient) BlockNumber(_ context.Context) (uint64, error) {
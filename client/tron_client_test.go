@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,13 +21,23 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+
+	"github.com/functionx/fx-tron-bridge/testutil"
 )
 
-const grpcUrl = "http://3.225.171.164:50051"
 const testTronPrivKey = ""
 
+// liveGrpcUrl returns the tron node used by the tests that need a real chain, they are skipped when it is not set.
+func liveGrpcUrl(t *testing.T) string {
+	grpcUrl := os.Getenv("TRON_TEST_GRPC_URL")
+	if len(grpcUrl) <= 0 {
+		t.Skip("TRON_TEST_GRPC_URL not set")
+	}
+	return grpcUrl
+}
+
 func TestNewTronClient(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,19 +49,17 @@ func TestNewTronClient(t *testing.T) {
 }
 
 func TestGetLastBlockNumber(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
-	if err != nil {
-		t.Fatal(err)
-	}
+	fakeTron := testutil.NewFakeTron(t)
+	fakeTron.SetBlockNumber(1234)
+	cli, err := NewTronGrpcClient(fakeTron.URL)
+	require.NoError(t, err)
 	lastBlockNumber, err := cli.BlockNumber(context.Background())
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log("lastBlockNumber:", lastBlockNumber)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1234), lastBlockNumber)
 }
 
 func TestWithMint(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -61,7 +71,7 @@ func TestWithMint(t *testing.T) {
 }
 
 func TestAllowance(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -73,7 +83,7 @@ func TestAllowance(t *testing.T) {
 }
 
 func TestTransaction(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -88,33 +98,30 @@ func TestTransaction(t *testing.T) {
 }
 
 func TestGasPrice(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
-	if err != nil {
-		t.Fatal(err)
-	}
+	fakeTron := testutil.NewFakeTron(t)
+	fakeTron.SetChainParameter("getEnergyFee", 280)
+	cli, err := NewTronGrpcClient(fakeTron.URL)
+	require.NoError(t, err)
 
 	gasPrice, err := cli.SuggestGasPrice(context.Background())
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Logf("gasPrice: %v", gasPrice.Uint64())
+	require.NoError(t, err)
+	require.Equal(t, uint64(280), gasPrice.Uint64())
 }
 
 func TestGetNowBlock(t *testing.T) {
-	grpcClient := client.NewGrpcClient("http://127.0.0.1:50051")
+	fakeTron := testutil.NewFakeTron(t)
+	fakeTron.SetBlockNumber(100)
+	grpcClient := client.NewGrpcClient(strings.TrimPrefix(fakeTron.URL, "http://"))
 	err := grpcClient.Start(grpc.WithInsecure())
-	if err != nil {
-		t.Fatal(err)
-	}
-	block, err := grpcClient.Client.GetNowBlock(context.Background(), &api.EmptyMessage{})
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log(block.BlockHeader.RawData.Number)
+	require.NoError(t, err)
+	block, err := grpcClient.Client.GetNowBlock2(context.Background(), &api.EmptyMessage{})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), block.BlockHeader.RawData.Number)
 }
 
 func TestGetChainParams(t *testing.T) {
-	grpcClient := client.NewGrpcClient("http://127.0.0.1:50051")
+	fakeTron := testutil.NewFakeTron(t)
+	grpcClient := client.NewGrpcClient(strings.TrimPrefix(fakeTron.URL, "http://"))
 	err := grpcClient.Start(grpc.WithInsecure())
 	require.NoError(t, err)
 
@@ -128,11 +135,11 @@ func TestGetChainParams(t *testing.T) {
 
 func TestGetLimit(t *testing.T) {
 	feeLimit := GetLimit(big.NewInt(280), 111542)
-	assert.Equal(t, 37478112, feeLimit)
+	assert.Equal(t, int64(37478112), feeLimit)
 }
 
 func TestEstimateGas(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -177,7 +184,7 @@ func TestEstimateGas(t *testing.T) {
 }
 
 func TestEnergy(t *testing.T) {
-	cli, err := NewTronGrpcClient(grpcUrl)
+	cli, err := NewTronGrpcClient(liveGrpcUrl(t))
 	if err != nil {
 		t.Fatal(err)
 	}
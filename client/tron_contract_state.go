@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+// contractState reads the bridge and token contracts through the generated bindings, TronClient and
+// TronHttpClient embed it with the bind.ContractCaller of their transport.
+type contractState struct {
+	caller bind.ContractCaller
+}
+
+// bridgeCaller binds the view methods of the FxBridgeTron contract at contractAddress.
+func (s contractState) bridgeCaller(contractAddress string) (*contract.FxBridgeTronCaller, error) {
+	contractAddr, err := contract.StringToAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return contract.NewFxBridgeTronCaller(contractAddr, s.caller)
+}
+
+// erc20Caller binds the view methods of the trc20 token at tokenAddress.
+func (s contractState) erc20Caller(tokenAddress string) (*contract.ERC20Caller, error) {
+	tokenAddr, err := contract.StringToAddress(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	return contract.NewERC20Caller(tokenAddr, s.caller)
+}
+
+func (s contractState) StateLastOracleSetNonce(contractAddress string) (uint64, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return 0, err
+	}
+	nonce, err := caller.StateLastOracleSetNonce(&bind.CallOpts{})
+	if err != nil {
+		return 0, err
+	}
+	return nonce.Uint64(), nil
+}
+
+func (s contractState) StateFxBridgeId(contractAddress string) (string, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return "", err
+	}
+	bridgeId, err := caller.StateFxBridgeId(&bind.CallOpts{})
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(bridgeId[:], "\x00")), nil
+}
+
+func (s contractState) StateLastOracleSetHeight(contractAddress string) (uint64, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return 0, err
+	}
+	height, err := caller.StateLaseOracleSetHeight(&bind.CallOpts{})
+	if err != nil {
+		return 0, err
+	}
+	return height.Uint64(), nil
+}
+
+func (s contractState) StateLastOracleSetCheckpoint(contractAddress string) ([32]byte, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return caller.StateLastOracleSetCheckpoint(&bind.CallOpts{})
+}
+
+func (s contractState) StatePowerThreshold(contractAddress string) (uint64, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return 0, err
+	}
+	powerThreshold, err := caller.StatePowerThreshold(&bind.CallOpts{})
+	if err != nil {
+		return 0, err
+	}
+	return powerThreshold.Uint64(), nil
+}
+
+// MakeCheckpoint asks the contract for the checkpoint of an oracle set, as stored in state_lastOracleSetCheckpoint.
+func (s contractState) MakeCheckpoint(contractAddress string, oracleSet crosschaintypes.OracleSet, fxBridgeId string) ([32]byte, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	oracles := make([]ethcommon.Address, len(oracleSet.Members))
+	powers := make([]*big.Int, len(oracleSet.Members))
+	for i, member := range oracleSet.Members {
+		if oracles[i], err = contract.StringToAddress(member.ExternalAddress); err != nil {
+			return [32]byte{}, err
+		}
+		powers[i] = new(big.Int).SetUint64(member.Power)
+	}
+	var bridgeId [32]byte
+	copy(bridgeId[:], fxBridgeId)
+	return caller.MakeCheckpoint(&bind.CallOpts{}, oracles, powers, new(big.Int).SetUint64(oracleSet.Nonce), bridgeId)
+}
+
+func (s contractState) LastBatchNonce(contractAddress string, erc20Address string) (uint64, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return 0, err
+	}
+	token, err := contract.StringToAddress(erc20Address)
+	if err != nil {
+		return 0, err
+	}
+	nonce, err := caller.LastBatchNonce(&bind.CallOpts{}, token)
+	if err != nil {
+		return 0, err
+	}
+	return nonce.Uint64(), nil
+}
+
+func (s contractState) Allowance(tokenAddress, owner, spender string) (*big.Int, error) {
+	caller, err := s.erc20Caller(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	ownerAddr, err := contract.StringToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	spenderAddr, err := contract.StringToAddress(spender)
+	if err != nil {
+		return nil, err
+	}
+	return caller.Allowance(&bind.CallOpts{}, ownerAddr, spenderAddr)
+}
+
+func (s contractState) BalanceOf(tokenAddress, owner string) (*big.Int, error) {
+	caller, err := s.erc20Caller(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	ownerAddr, err := contract.StringToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	return caller.BalanceOf(&bind.CallOpts{}, ownerAddr)
+}
+
+func (s contractState) GetTokenStatus(contractAddress, tokenAddress string) (bool, bool, bool, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return false, false, false, err
+	}
+	token, err := contract.StringToAddress(tokenAddress)
+	if err != nil {
+		return false, false, false, err
+	}
+	status, err := caller.TokenStatus(&bind.CallOpts{}, token)
+	if err != nil {
+		return false, false, false, err
+	}
+	return status.IsOriginated, status.IsActive, status.IsExist, nil
+}
+
+func (s contractState) GetBridgeTokenList(contractAddress string) ([]contract.FxBridgeToken, error) {
+	caller, err := s.bridgeCaller(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return caller.GetBridgeTokenList(&bind.CallOpts{})
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	tronabi "github.com/fbsobreira/gotron-sdk/pkg/contract"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+const (
+	bridgeContractName = "FxBridgeTron"
+	// the deployer pays all the energy of the contract calls, up to originEnergyLimit per call
+	consumeUserResourcePercent = 100
+	originEnergyLimit          = 10000000
+)
+
+// DeployBridgeContract deploys the FxBridgeTron bytecode from the address of privKey and returns the
+// address of the new contract once the deployment is in a block.
+func (c *TronClient) DeployBridgeContract(privKey *ecdsa.PrivateKey, feeLimit int64, timeout time.Duration) (string, *core.TransactionInfo, error) {
+	bridgeAbi, err := tronabi.JSONtoABI(contract.FxBridgeTronMetaData.ABI)
+	if err != nil {
+		return "", nil, err
+	}
+	owner := address.PubkeyToAddress(privKey.PublicKey).String()
+	tx, err := c.DeployContract(owner, bridgeContractName, bridgeAbi, contract.FxBridgeTronMetaData.Bin,
+		feeLimit, consumeUserResourcePercent, originEnergyLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	if tx.GetResult().GetCode() != 0 {
+		return "", nil, fmt.Errorf("deploy contract: %s", string(tx.GetResult().GetMessage()))
+	}
+	if err = SignTransaction(tx.Transaction, privKey); err != nil {
+		return "", nil, err
+	}
+	if _, err = c.BroadcastTx(tx); err != nil {
+		return "", nil, err
+	}
+	info, err := c.waitReceipt(tx.Txid, timeout)
+	if err != nil {
+		return "", info, err
+	}
+	if len(info.GetContractAddress()) <= 0 {
+		return "", info, fmt.Errorf("deploy transaction %x has no contract address", tx.Txid)
+	}
+	return address.Address(info.GetContractAddress()).String(), info, nil
+}
+
+// PackBridgeInit packs the init call that sets the fx bridge id, the power threshold and the oracle set
+// of a newly deployed FxBridgeTron contract.
+func PackBridgeInit(fxBridgeId string, powerThreshold uint64, oracleSet crosschaintypes.OracleSet) ([]byte, error) {
+	if len(fxBridgeId) > 32 {
+		return nil, fmt.Errorf("fx bridge id is longer than 32 bytes: %s", fxBridgeId)
+	}
+	if len(oracleSet.Members) <= 0 {
+		return nil, fmt.Errorf("oracle set %d has no members", oracleSet.Nonce)
+	}
+	var bridgeId [32]byte
+	copy(bridgeId[:], fxBridgeId)
+	oracles := make([]ethcommon.Address, 0, len(oracleSet.Members))
+	powers := make([]*big.Int, 0, len(oracleSet.Members))
+	totalPower := uint64(0)
+	for _, member := range oracleSet.Members {
+		oracle, err := contract.StringToAddress(member.ExternalAddress)
+		if err != nil {
+			return nil, err
+		}
+		oracles = append(oracles, oracle)
+		powers = append(powers, new(big.Int).SetUint64(member.Power))
+		totalPower += member.Power
+	}
+	if totalPower < powerThreshold {
+		return nil, fmt.Errorf("oracle set %d total power %d is below the power threshold %d", oracleSet.Nonce, totalPower, powerThreshold)
+	}
+	return PackBridgeCall("init", bridgeId, new(big.Int).SetUint64(powerThreshold), oracles, powers)
+}
+
+// CheckBridgeInit verifies that the contract stores fxBridgeId and the checkpoint of the oracle set members.
+func (c *TronClient) CheckBridgeInit(contractAddress, fxBridgeId string, oracleSet crosschaintypes.OracleSet) error {
+	bridgeId, err := c.StateFxBridgeId(contractAddress)
+	if err != nil {
+		return err
+	}
+	if bridgeId != fxBridgeId {
+		return fmt.Errorf("contract fx bridge id is %q, expect %q", bridgeId, fxBridgeId)
+	}
+	oracleSet.Nonce, err = c.StateLastOracleSetNonce(contractAddress)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := c.MakeCheckpoint(contractAddress, oracleSet, fxBridgeId)
+	if err != nil {
+		return err
+	}
+	onChainCheckpoint, err := c.StateLastOracleSetCheckpoint(contractAddress)
+	if err != nil {
+		return err
+	}
+	if checkpoint != onChainCheckpoint {
+		return fmt.Errorf("contract checkpoint %x differs from the oracle set checkpoint %x at nonce %d", onChainCheckpoint, checkpoint, oracleSet.Nonce)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func TestDeployBridgeContract(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	bridgeAddr, info, err := cli.DeployBridgeContract(privKey, 2000000000, 10*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, testutil.ContractAddress(info.Id, address.PubkeyToAddress(privKey.PublicKey).Bytes()).String(), bridgeAddr)
+
+	oracleSet := crosschaintypes.OracleSet{Nonce: 3}
+	oracles := make([]ethcommon.Address, 0, 2)
+	powers := make([]*big.Int, 0, 2)
+	for _, power := range []uint64{2147483648, 2147483647} {
+		oracleKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		oracle := address.PubkeyToAddress(oracleKey.PublicKey).String()
+		oracleSet.Members = append(oracleSet.Members, crosschaintypes.BridgeValidator{ExternalAddress: oracle, Power: power})
+		oracleAddr, err := contract.StringToAddress(oracle)
+		require.NoError(t, err)
+		oracles = append(oracles, oracleAddr)
+		powers = append(powers, new(big.Int).SetUint64(power))
+	}
+	_, err = PackBridgeInit("tron", fxtronbridge.ThresholdVotePower, crosschaintypes.OracleSet{Nonce: 3})
+	require.Error(t, err)
+	_, err = PackBridgeInit("tron", 1<<33, oracleSet)
+	require.Error(t, err)
+	data, err := PackBridgeInit("tron", fxtronbridge.ThresholdVotePower, oracleSet)
+	require.NoError(t, err)
+	call, err := DecodeBridgeCall(data)
+	require.NoError(t, err)
+	require.Contains(t, call, `init(_fxBridgeId: "tron", _powerThreshold: 1870887753`)
+
+	require.NoError(t, fakeTron.SetConstantResult(bridgeAddr, testutil.Selector("init(bytes32,uint256,address[],uint256[])")))
+	_, err = cli.Transact(privKey, bridgeAddr, data, 10*time.Second)
+	require.NoError(t, err)
+
+	var bridgeId [32]byte
+	copy(bridgeId[:], "tron")
+	checkpoint := crypto.Keccak256Hash([]byte("checkpoint"))
+	require.NoError(t, fakeTron.SetMethodResult(bridgeAddr, "state_fxBridgeId", bridgeId))
+	require.NoError(t, fakeTron.SetMethodResult(bridgeAddr, "state_lastOracleSetNonce", big.NewInt(0)))
+	require.NoError(t, fakeTron.SetMethodResult(bridgeAddr, "state_lastOracleSetCheckpoint", checkpoint))
+	require.NoError(t, fakeTron.SetMethodResult(bridgeAddr, "makeCheckpoint", ethcommon.Hash{}))
+	makeCheckpoint, err := PackBridgeCall("makeCheckpoint", oracles, powers, big.NewInt(0), bridgeId)
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(bridgeAddr, makeCheckpoint, checkpoint.Bytes()))
+
+	require.NoError(t, cli.CheckBridgeInit(bridgeAddr, "tron", oracleSet))
+	require.EqualError(t, cli.CheckBridgeInit(bridgeAddr, "eth", oracleSet), `contract fx bridge id is "tron", expect "eth"`)
+	oracleSet.Members[0].Power--
+	require.ErrorContains(t, cli.CheckBridgeInit(bridgeAddr, "tron", oracleSet), "differs from the oracle set checkpoint")
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/abi"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+// FxDestination encodes a fx bech32 account as the bytes32 _destination of sendToFx, the address
+// is right aligned like an abi encoded address.
+func FxDestination(receiver string) ([32]byte, error) {
+	var destination [32]byte
+	accAddress, err := sdk.AccAddressFromBech32(receiver)
+	if err != nil {
+		return destination, err
+	}
+	if len(accAddress) != 20 {
+		return destination, fmt.Errorf("receiver %s is not a 20 bytes account", receiver)
+	}
+	copy(destination[12:], accAddress)
+	return destination, nil
+}
+
+// TargetIBC encodes the ibc target of sendToFx, e.g. px/transfer/channel-0, an empty target keeps the tokens on fx core.
+func TargetIBC(targetIbc string) ([32]byte, error) {
+	var target [32]byte
+	if len(targetIbc) > 32 {
+		return target, fmt.Errorf("target ibc is longer than 32 bytes: %s", targetIbc)
+	}
+	copy(target[:], targetIbc)
+	return target, nil
+}
+
+// ApproveBridge approves bridgeAddr to spend amount of token from the address of privKey when the current
+// allowance is lower, it returns nil info when no approval is needed.
+func (c *TronClient) ApproveBridge(privKey *ecdsa.PrivateKey, token, bridgeAddr string, amount *big.Int, timeout time.Duration) (*core.TransactionInfo, error) {
+	owner := address.PubkeyToAddress(privKey.PublicKey).String()
+	allowance, err := c.Allowance(token, owner, bridgeAddr)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+	data, err := abi.Pack("approve(address,uint256)", []abi.Param{
+		{"address": bridgeAddr},
+		{"uint256": amount},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.Transact(privKey, token, data, timeout)
+}
+
+// SendToFx calls sendToFx on bridgeAddr to deposit amount of token to receiver on fx core.
+func (c *TronClient) SendToFx(privKey *ecdsa.PrivateKey, bridgeAddr, token, receiver, targetIbc string, amount *big.Int, timeout time.Duration) (*core.TransactionInfo, error) {
+	tokenAddr, err := contract.StringToAddress(token)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := FxDestination(receiver)
+	if err != nil {
+		return nil, err
+	}
+	target, err := TargetIBC(targetIbc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := PackBridgeCall("sendToFx", tokenAddr, destination, target, amount)
+	if err != nil {
+		return nil, err
+	}
+	return c.Transact(privKey, bridgeAddr, data, timeout)
+}
+
+// SendToFxEvent finds the SendToFxEvent emitted by the transaction of info.
+func (c *TronClient) SendToFxEvent(bridgeAddr string, info *core.TransactionInfo) (*contract.FxBridgeTronSendToFxEvent, error) {
+	events, err := c.QueryBlockEvent(bridgeAddr, uint64(info.GetBlockNumber()))
+	if err != nil {
+		return nil, err
+	}
+	txHash := ethcommon.BytesToHash(info.GetId())
+	for _, event := range events {
+		sendToFxEvent, ok := event.(*contract.FxBridgeTronSendToFxEvent)
+		if ok && bytes.Equal(sendToFxEvent.Raw.TxHash.Bytes(), txHash.Bytes()) {
+			return sendToFxEvent, nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %x emits no SendToFxEvent in block %d", info.GetId(), info.GetBlockNumber())
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func TestSendToFx(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	fakeTron.SetBlockNumber(100)
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := address.PubkeyToAddress(privKey.PublicKey)
+	token := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	receiver := sdk.AccAddress(crypto.Keccak256([]byte("receiver"))[:20]).String()
+	amount := big.NewInt(1000000)
+
+	require.NoError(t, fakeTron.SetConstantResult(token, testutil.Selector("allowance(address,address)"), big.NewInt(10).FillBytes(make([]byte, 32))))
+	require.NoError(t, fakeTron.SetConstantResult(token, testutil.Selector("approve(address,uint256)")))
+	info, err := cli.ApproveBridge(privKey, token, testBridgeAddr, amount, 10*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	require.NoError(t, fakeTron.SetConstantResult(token, testutil.Selector("allowance(address,address)"), amount.FillBytes(make([]byte, 32))))
+	info, err = cli.ApproveBridge(privKey, token, testBridgeAddr, amount, 10*time.Second)
+	require.NoError(t, err)
+	require.Nil(t, info)
+	require.Len(t, fakeTron.Transactions(), 1)
+
+	_, err = cli.SendToFx(privKey, testBridgeAddr, token, "fx1invalid", "", amount, 10*time.Second)
+	require.Error(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("sendToFx(address,bytes32,bytes32,uint256)")))
+	info, err = cli.SendToFx(privKey, testBridgeAddr, token, receiver, "px/transfer/channel-0", amount, 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, fakeTron.Transactions(), 2)
+	_, err = cli.SendToFxEvent(testBridgeAddr, info)
+	require.Error(t, err)
+
+	tokenAddr, err := contract.StringToAddress(token)
+	require.NoError(t, err)
+	senderAddr, err := contract.StringToAddress(sender.String())
+	require.NoError(t, err)
+	destination, err := FxDestination(receiver)
+	require.NoError(t, err)
+	target, err := TargetIBC("px/transfer/channel-0")
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.AddEvent(info.BlockNumber, testBridgeAddr, crypto.Keccak256([]byte("other")), "SendToFxEvent",
+		tokenAddr, senderAddr, destination, target, big.NewInt(1), big.NewInt(7)))
+	require.NoError(t, fakeTron.AddEvent(info.BlockNumber, testBridgeAddr, info.Id, "SendToFxEvent",
+		tokenAddr, senderAddr, destination, target, amount, big.NewInt(8)))
+
+	event, err := cli.SendToFxEvent(testBridgeAddr, info)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), event.GetEventNonce())
+	claim := event.ToMsg(uint64(info.BlockNumber), "fx1bridger").(*crosschaintypes.MsgSendToFxClaim)
+	require.Equal(t, receiver, claim.Receiver)
+	require.Equal(t, hex.EncodeToString([]byte("px/transfer/channel-0")), claim.TargetIbc)
+	require.Equal(t, sender.String(), claim.Sender)
+	require.Equal(t, amount.String(), claim.Amount.String())
+}
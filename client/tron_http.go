@@ -0,0 +1,265 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+const tronHttpTimeout = 30 * time.Second
+
+// TronHttpClient talks to the tron http api of a full node or TronGrid, for providers that do not expose grpc.
+// It is a bridge EventSource and BridgeState, and builds, permission checks and broadcasts offline transactions.
+type TronHttpClient struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+	contractState
+}
+
+// NewTronHttpClient connects to the http api at endpoint, e.g. https://api.trongrid.io, headers are sent
+// with every request, e.g. TRON-PRO-API-KEY.
+func NewTronHttpClient(endpoint string, headers map[string]string) (*TronHttpClient, error) {
+	parseUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if parseUrl.Scheme != "http" && parseUrl.Scheme != "https" {
+		return nil, fmt.Errorf("tron http api needs an http or https url: %s", endpoint)
+	}
+	tronHttpClient := &TronHttpClient{
+		endpoint: strings.TrimSuffix(parseUrl.String(), "/"),
+		headers:  headers,
+		client:   &http.Client{Timeout: tronHttpTimeout},
+	}
+	tronHttpClient.contractState = contractState{caller: httpContractCaller{client: tronHttpClient}}
+	return tronHttpClient, nil
+}
+
+type httpBlock struct {
+	BlockHeader struct {
+		RawData struct {
+			Number int64 `json:"number"`
+		} `json:"raw_data"`
+	} `json:"block_header"`
+}
+
+type httpTransactionInfo struct {
+	Id              string   `json:"id"`
+	Fee             int64    `json:"fee"`
+	BlockNumber     int64    `json:"blockNumber"`
+	ContractResult  []string `json:"contractResult"`
+	ContractAddress string   `json:"contract_address"`
+	Receipt         struct {
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		Result           string `json:"result"`
+	} `json:"receipt"`
+	Log []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"log"`
+}
+
+type httpConstantResult struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"result"`
+	EnergyUsed     int64    `json:"energy_used"`
+	ConstantResult []string `json:"constant_result"`
+	Transaction    struct {
+		Ret []struct {
+			ContractRet string `json:"contractRet"`
+		} `json:"ret"`
+	} `json:"transaction"`
+}
+
+func (c *TronHttpClient) BlockNumber(_ context.Context) (uint64, error) {
+	var block httpBlock
+	if err := c.post("/wallet/getnowblock", struct{}{}, &block); err != nil {
+		return 0, err
+	}
+	return uint64(block.BlockHeader.RawData.Number), nil
+}
+
+func (c *TronHttpClient) QueryBlockEvent(contractAddress string, blockNumber uint64) ([]contract.IEvent, error) {
+	transactionInfos, err := c.transactionInfoByBlockNum(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return blockEvents(contractAddress, transactionInfos)
+}
+
+func (c *TronHttpClient) QueryOracleSetUpdatedEvent(contractAddress string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error) {
+	transactionInfos, err := c.transactionInfoByBlockNum(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return oracleSetUpdatedEvents(contractAddress, transactionInfos)
+}
+
+// CallContract runs data against contractAddress as a constant call from owner, like TronClient.CallContract.
+func (c *TronHttpClient) CallContract(owner, contractAddress string, data []byte) ([]byte, error) {
+	result, _, err := c.constantCall(owner, contractAddress, data)
+	return result, err
+}
+
+// constantCall returns the result of a constant call and the energy it used.
+func (c *TronHttpClient) constantCall(owner, contractAddress string, data []byte) ([]byte, int64, error) {
+	request := map[string]interface{}{
+		"owner_address":    owner,
+		"contract_address": contractAddress,
+		"data":             hex.EncodeToString(data),
+		"visible":          true,
+	}
+	var response httpConstantResult
+	if err := c.post("/wallet/triggerconstantcontract", request, &response); err != nil {
+		return nil, 0, err
+	}
+	var result []byte
+	if len(response.ConstantResult) > 0 {
+		var err error
+		if result, err = hex.DecodeString(response.ConstantResult[0]); err != nil {
+			return nil, 0, fmt.Errorf("constant result: %w", err)
+		}
+	}
+	if !response.Result.Result {
+		return nil, 0, fmt.Errorf("call reverted: %s %s", response.Result.Code, nodeMessage(response.Result.Message))
+	}
+	for _, ret := range response.Transaction.Ret {
+		if len(ret.ContractRet) > 0 && ret.ContractRet != core.Transaction_Result_SUCCESS.String() {
+			return nil, 0, fmt.Errorf("call reverted: %s", RevertReason(result))
+		}
+	}
+	return result, response.EnergyUsed, nil
+}
+
+// nodeMessage decodes the hex messages of the node errors, other messages are returned as they are.
+func nodeMessage(message string) string {
+	decoded, err := hex.DecodeString(message)
+	if err != nil {
+		return message
+	}
+	return string(decoded)
+}
+
+// transactionInfoByBlockNum converts the transaction infos of a block to their grpc form, the node answers {}
+// for a block without transactions.
+func (c *TronHttpClient) transactionInfoByBlockNum(blockNumber uint64) ([]*core.TransactionInfo, error) {
+	var raw json.RawMessage
+	if err := c.post("/wallet/gettransactioninfobyblocknum", map[string]uint64{"num": blockNumber}, &raw); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		return []*core.TransactionInfo{}, nil
+	}
+	var httpInfos []httpTransactionInfo
+	if err := json.Unmarshal(raw, &httpInfos); err != nil {
+		return nil, err
+	}
+	transactionInfos := make([]*core.TransactionInfo, 0, len(httpInfos))
+	for _, httpInfo := range httpInfos {
+		transactionInfo, err := httpInfo.toProto()
+		if err != nil {
+			return nil, fmt.Errorf("block %d transaction %s: %w", blockNumber, httpInfo.Id, err)
+		}
+		transactionInfos = append(transactionInfos, transactionInfo)
+	}
+	return transactionInfos, nil
+}
+
+func (info httpTransactionInfo) toProto() (*core.TransactionInfo, error) {
+	var err error
+	transactionInfo := &core.TransactionInfo{
+		Fee:         info.Fee,
+		BlockNumber: info.BlockNumber,
+		Receipt:     &core.ResourceReceipt{EnergyUsageTotal: info.Receipt.EnergyUsageTotal},
+	}
+	if transactionInfo.Id, err = hex.DecodeString(info.Id); err != nil {
+		return nil, err
+	}
+	for _, contractResult := range info.ContractResult {
+		result, err := hex.DecodeString(contractResult)
+		if err != nil {
+			return nil, err
+		}
+		transactionInfo.ContractResult = append(transactionInfo.ContractResult, result)
+	}
+	if transactionInfo.ContractAddress, err = hex.DecodeString(info.ContractAddress); err != nil {
+		return nil, err
+	}
+	if result, ok := core.Transaction_ResultContractResult_value[info.Receipt.Result]; ok {
+		transactionInfo.Receipt.Result = core.Transaction_ResultContractResult(result)
+	}
+	for _, httpLog := range info.Log {
+		log := &core.TransactionInfo_Log{}
+		if log.Address, err = hex.DecodeString(httpLog.Address); err != nil {
+			return nil, err
+		}
+		if log.Data, err = hex.DecodeString(httpLog.Data); err != nil {
+			return nil, err
+		}
+		for _, topic := range httpLog.Topics {
+			topicBytes, err := hex.DecodeString(topic)
+			if err != nil {
+				return nil, err
+			}
+			log.Topics = append(log.Topics, topicBytes)
+		}
+		transactionInfo.Log = append(transactionInfo.Log, log)
+	}
+	return transactionInfo, nil
+}
+
+// post sends request as json to path and decodes the json response, the node reports failures in an Error field.
+func (c *TronHttpClient) post(path string, request, response interface{}) error {
+	start := time.Now()
+	defer func() {
+		fxtronbridge.RpcLatencyProm.WithLabelValues("tron-http", path).Observe(time.Since(start).Seconds())
+	}()
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tron http %s: %s %s", path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	var nodeErr struct {
+		Error string `json:"Error"`
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(respBody), []byte("{")) && json.Unmarshal(respBody, &nodeErr) == nil && len(nodeErr.Error) > 0 {
+		return fmt.Errorf("tron http %s: %s", path, nodeErr.Error)
+	}
+	return json.Unmarshal(respBody, response)
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var _ bind.ContractCaller = httpContractCaller{}
+
+// httpContractCaller lets the generated bindings read the contract state over the tron http api.
+type httpContractCaller struct {
+	client *TronHttpClient
+}
+
+func (c httpContractCaller) CodeAt(_ context.Context, contractAddr ethcommon.Address, _ *big.Int) ([]byte, error) {
+	return c.client.contractCode(TronAddress(contractAddr).String())
+}
+
+// CallContract runs a constant call, tron only serves the latest state so blockNumber is ignored.
+func (c httpContractCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if call.To == nil {
+		return nil, fmt.Errorf("constant call without contract address")
+	}
+	return c.client.CallContract(TronAddress(call.From).String(), TronAddress(*call.To).String(), call.Data)
+}
+
+func (c *TronHttpClient) contractCode(contractAddress string) ([]byte, error) {
+	var response struct {
+		Bytecode string `json:"bytecode"`
+	}
+	if err := c.post("/wallet/getcontract", map[string]interface{}{"value": contractAddress, "visible": true}, &response); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(response.Bytecode)
+}
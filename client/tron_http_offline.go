@@ -0,0 +1,201 @@
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"google.golang.org/protobuf/proto"
+)
+
+type httpPermission struct {
+	Id             int32  `json:"id"`
+	PermissionName string `json:"permission_name"`
+	Threshold      int64  `json:"threshold"`
+	Operations     string `json:"operations"`
+	Keys           []struct {
+		Address string `json:"address"`
+		Weight  int64  `json:"weight"`
+	} `json:"keys"`
+}
+
+type httpAccount struct {
+	Address          string           `json:"address"`
+	OwnerPermission  *httpPermission  `json:"owner_permission"`
+	ActivePermission []httpPermission `json:"active_permission"`
+}
+
+// BuildOfflineTx builds an unsigned transaction like TronClient.BuildOfflineTx over the http api.
+func (c *TronHttpClient) BuildOfflineTx(owner, contractAddress string, data []byte, feeLimit int64, permissionId int32, expiration time.Duration) (*OfflineTx, error) {
+	if feeLimit <= 0 {
+		_, energy, err := c.constantCall(owner, contractAddress, data)
+		if err != nil {
+			return nil, err
+		}
+		gasPrice, err := c.energyFee()
+		if err != nil {
+			return nil, err
+		}
+		feeLimit = GetLimit(gasPrice, uint64(energy))
+	}
+	request := map[string]interface{}{
+		"owner_address":    owner,
+		"contract_address": contractAddress,
+		"data":             hex.EncodeToString(data),
+		"fee_limit":        feeLimit,
+		"visible":          true,
+	}
+	var response struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		Transaction struct {
+			RawDataHex string `json:"raw_data_hex"`
+		} `json:"transaction"`
+	}
+	if err := c.post("/wallet/triggersmartcontract", request, &response); err != nil {
+		return nil, err
+	}
+	if !response.Result.Result {
+		return nil, fmt.Errorf("trigger contract: %s %s", response.Result.Code, nodeMessage(response.Result.Message))
+	}
+	rawData, err := hex.DecodeString(response.Transaction.RawDataHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := &core.Transaction{RawData: new(core.TransactionRaw)}
+	if err = proto.Unmarshal(rawData, tx.RawData); err != nil {
+		return nil, err
+	}
+	if len(tx.RawData.Contract) != 1 {
+		return nil, fmt.Errorf("expect one contract in the transaction, got %d", len(tx.RawData.Contract))
+	}
+	tx.RawData.Contract[0].PermissionId = permissionId
+	if expiration > 0 {
+		tx.RawData.Expiration = time.Now().Add(expiration).UnixMilli()
+	}
+	return NewOfflineTx(tx)
+}
+
+func (c *TronHttpClient) energyFee() (*big.Int, error) {
+	var response struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+	if err := c.post("/wallet/getchainparameters", struct{}{}, &response); err != nil {
+		return nil, err
+	}
+	for _, parameter := range response.ChainParameter {
+		if parameter.Key == "getEnergyFee" {
+			return big.NewInt(parameter.Value), nil
+		}
+	}
+	return nil, fmt.Errorf("not gasPrice")
+}
+
+// CheckPermission is TronClient.CheckPermission over the http api.
+func (c *TronHttpClient) CheckPermission(offlineTx *OfflineTx) (*PermissionWeight, error) {
+	var response httpAccount
+	if err := c.post("/wallet/getaccount", map[string]interface{}{"address": offlineTx.Owner, "visible": true}, &response); err != nil {
+		return nil, fmt.Errorf("get account %s: %w", offlineTx.Owner, err)
+	}
+	account, err := response.toProto(offlineTx.Owner)
+	if err != nil {
+		return nil, err
+	}
+	return permissionWeight(account, offlineTx)
+}
+
+// toProto converts the account with base58 addresses, an account that does not exist has no permission.
+func (a httpAccount) toProto(owner string) (*core.Account, error) {
+	ownerAddr, err := address.Base58ToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	account := &core.Account{Address: ownerAddr.Bytes()}
+	if a.OwnerPermission != nil {
+		if account.OwnerPermission, err = a.OwnerPermission.toProto(core.Permission_Owner); err != nil {
+			return nil, err
+		}
+	}
+	for _, active := range a.ActivePermission {
+		permission, err := active.toProto(core.Permission_Active)
+		if err != nil {
+			return nil, err
+		}
+		account.ActivePermission = append(account.ActivePermission, permission)
+	}
+	return account, nil
+}
+
+func (p httpPermission) toProto(permissionType core.Permission_PermissionType) (*core.Permission, error) {
+	operations, err := hex.DecodeString(p.Operations)
+	if err != nil {
+		return nil, err
+	}
+	permission := &core.Permission{Type: permissionType, Id: p.Id, PermissionName: p.PermissionName, Threshold: p.Threshold, Operations: operations}
+	for _, key := range p.Keys {
+		keyAddr, err := address.Base58ToAddress(key.Address)
+		if err != nil {
+			return nil, err
+		}
+		permission.Keys = append(permission.Keys, &core.Key{Address: keyAddr.Bytes(), Weight: key.Weight})
+	}
+	return permission, nil
+}
+
+// BroadcastOfflineTx is TronClient.BroadcastOfflineTx over the http api.
+func (c *TronHttpClient) BroadcastOfflineTx(offlineTx *OfflineTx, timeout time.Duration) (*core.TransactionInfo, error) {
+	tx, txId, err := offlineTx.broadcastable(c.CheckPermission)
+	if err != nil {
+		return nil, err
+	}
+	txBytes, err := proto.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err = c.post("/wallet/broadcasthex", map[string]string{"transaction": hex.EncodeToString(txBytes)}, &response); err != nil {
+		return nil, err
+	}
+	if !response.Result {
+		return nil, fmt.Errorf("bad transaction: %s %s", response.Code, nodeMessage(response.Message))
+	}
+	return c.waitReceipt(txId, timeout)
+}
+
+// waitReceipt polls the receipt of txId for up to timeout, the node answers {} until the transaction is in a block.
+func (c *TronHttpClient) waitReceipt(txId []byte, timeout time.Duration) (*core.TransactionInfo, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var response httpTransactionInfo
+		if err := c.post("/wallet/gettransactioninfobyid", map[string]string{"value": hex.EncodeToString(txId)}, &response); err != nil {
+			return nil, fmt.Errorf("wait transaction %s: %w", hex.EncodeToString(txId), err)
+		}
+		if len(response.Id) > 0 {
+			info, err := response.toProto()
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Equal(info.Id, txId) {
+				return info, receiptError(txId, info)
+			}
+		}
+		if time.Now().Add(tronBlockInterval).After(deadline) {
+			return nil, fmt.Errorf("wait transaction %s: timeout after %s", hex.EncodeToString(txId), timeout)
+		}
+		time.Sleep(tronBlockInterval)
+	}
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/stretchr/testify/require"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+// newTestTronHttpClient serves the json recorded from a tron full node in testdata/tron_http, requests are
+// answered from <path>.json, a block number from <path>_<num>.json and a contract call from <path>_<constant>.json.
+func newTestTronHttpClient(t *testing.T, constant string) *TronHttpClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get(fxtronbridge.TronApiKeyHeader) != "api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			Num *uint64 `json:"num"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := filepath.Base(r.URL.Path)
+		if request.Num != nil {
+			name = fmt.Sprintf("%s_%d", name, *request.Num)
+		}
+		if len(constant) > 0 && name == "triggerconstantcontract" {
+			name = name + "_" + constant
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "tron_http", name+".json"))
+		if err != nil {
+			_, _ = fmt.Fprintf(w, `{"Error":"class java.lang.NullPointerException : %s"}`, name)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	httpClient, err := NewTronHttpClient(server.URL+"/", map[string]string{fxtronbridge.TronApiKeyHeader: "api-key"})
+	require.NoError(t, err)
+	return httpClient
+}
+
+func TestTronHttpBlockNumber(t *testing.T) {
+	httpClient := newTestTronHttpClient(t, "")
+	blockNumber, err := httpClient.BlockNumber(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(45132228), blockNumber)
+}
+
+func TestTronHttpQueryBlockEvent(t *testing.T) {
+	httpClient := newTestTronHttpClient(t, "")
+
+	events, err := httpClient.QueryBlockEvent(testBridgeAddr, 45132200)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	sendToFxEvent, ok := events[0].(*contract.FxBridgeTronSendToFxEvent)
+	require.True(t, ok)
+	require.Equal(t, uint64(12), sendToFxEvent.GetEventNonce())
+	require.Equal(t, big.NewInt(1000000), sendToFxEvent.Amount)
+	require.Equal(t, ethCommon.HexToAddress("0xa614f803b6fd780986a42c78ec9c7f77e6ded13c"), sendToFxEvent.TokenContract)
+	require.Equal(t, ethCommon.HexToHash("c78fb7b2e3c9c2e712beb3f0810da51cfd22c24f931002cf7b8fe9ec042de50c"), sendToFxEvent.GetTxHash())
+
+	oracleSetUpdatedEvent, ok := events[1].(*contract.FxBridgeTronOracleSetUpdatedEvent)
+	require.True(t, ok)
+	require.Equal(t, uint64(13), oracleSetUpdatedEvent.GetEventNonce())
+	require.Equal(t, big.NewInt(5), oracleSetUpdatedEvent.NewOracleSetNonce)
+
+	oracleSetUpdatedEvents, err := httpClient.QueryOracleSetUpdatedEvent(testBridgeAddr, 45132200)
+	require.NoError(t, err)
+	require.Len(t, oracleSetUpdatedEvents, 1)
+	require.Equal(t, oracleSetUpdatedEvent, oracleSetUpdatedEvents[0])
+
+	events, err = httpClient.QueryBlockEvent(testBridgeAddr, 45132201)
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+
+	_, err = httpClient.QueryBlockEvent(testBridgeAddr, 45132202)
+	require.ErrorContains(t, err, "NullPointerException")
+}
+
+// TestTronHttpMatchesGrpc checks the http source decodes the same events as the grpc source.
+func TestTronHttpMatchesGrpc(t *testing.T) {
+	tronClient, fakeTron := NewTestTronClient(t)
+	fakeTron.SetBlockNumber(45132228)
+	var destination [32]byte
+	copy(destination[12:], ethCommon.HexToAddress("0x7b3a8a5e0ea1f2d3b8c1c4e9b0d36a7e95f5d0a2").Bytes())
+	require.NoError(t, fakeTron.AddEvent(45132200, testBridgeAddr, ethCommon.FromHex("c78fb7b2e3c9c2e712beb3f0810da51cfd22c24f931002cf7b8fe9ec042de50c"), "SendToFxEvent",
+		ethCommon.HexToAddress("0xa614f803b6fd780986a42c78ec9c7f77e6ded13c"), ethCommon.HexToAddress("0x3e2b9a0c3f35d4a8a1e9c1bf0f7d2b6d4e8f1a23"),
+		destination, [32]byte{}, big.NewInt(1000000), big.NewInt(12)))
+	grpcEvents, err := tronClient.QueryBlockEvent(testBridgeAddr, 45132200)
+	require.NoError(t, err)
+
+	httpEvents, err := newTestTronHttpClient(t, "").QueryBlockEvent(testBridgeAddr, 45132200)
+	require.NoError(t, err)
+	require.Equal(t, grpcEvents[0], httpEvents[0])
+}
+
+func TestTronHttpCallContract(t *testing.T) {
+	data := testutil.Selector("state_lastEventNonce()")
+	result, err := newTestTronHttpClient(t, "").CallContract("TFysCB929XGezbnyumoFScyevjDggu3BPq", testBridgeAddr, data)
+	require.NoError(t, err)
+	nonce, err := fxBridgeAbi.Unpack("state_lastEventNonce", result)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(12), nonce[0])
+
+	_, err = newTestTronHttpClient(t, "revert").CallContract("TFysCB929XGezbnyumoFScyevjDggu3BPq", testBridgeAddr, data)
+	require.EqualError(t, err, "call reverted: Pausable: paused")
+
+	_, err = newTestTronHttpClient(t, "validate").CallContract("TFysCB929XGezbnyumoFScyevjDggu3BPq", testBridgeAddr, data)
+	require.EqualError(t, err, "call reverted: CONTRACT_VALIDATE_ERROR contract does not exists")
+}
+
+func TestNewTronHttpClient(t *testing.T) {
+	_, err := NewTronHttpClient("grpc://127.0.0.1:50051", nil)
+	require.Error(t, err)
+
+	httpClient, err := NewTronHttpClient("http://127.0.0.1:1", nil)
+	require.NoError(t, err)
+	_, err = httpClient.BlockNumber(context.Background())
+	require.Error(t, err)
+}
+
+// TestTronHttpBridgeState checks the http client reads the same bridge state as the grpc client.
+func TestTronHttpBridgeState(t *testing.T) {
+	tronClient, fakeTron := NewTestTronClient(t)
+	httpClient, err := NewTronHttpClient(fakeTron.ServeHttp(t), nil)
+	require.NoError(t, err)
+
+	const tokenAddr = "TLBaRhANQoJFTqre9Nf1mjuwNWjCJeYqUL"
+	token, err := contract.StringToAddress(tokenAddr)
+	require.NoError(t, err)
+	tokens := []contract.FxBridgeToken{{Addr: token, Name: "Tether USD", Symbol: "USDT", Decimals: 6}}
+	checkpoint := ethCommon.BytesToHash([]byte("checkpoint"))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_lastOracleSetNonce", big.NewInt(5)))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_lastOracleSetCheckpoint", checkpoint))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "state_powerThreshold", big.NewInt(1870887754)))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "makeCheckpoint", checkpoint))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "lastBatchNonce", big.NewInt(7)))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "tokenStatus", false, true, true))
+	require.NoError(t, fakeTron.SetMethodResult(testBridgeAddr, "getBridgeTokenList", tokens))
+	require.NoError(t, fakeTron.SetConstantResult(tokenAddr, testutil.Selector("balanceOf(address)"), big.NewInt(1000).FillBytes(make([]byte, 32))))
+
+	for _, state := range []interface {
+		StateLastOracleSetNonce(contractAddress string) (uint64, error)
+		StateLastOracleSetCheckpoint(contractAddress string) ([32]byte, error)
+		StatePowerThreshold(contractAddress string) (uint64, error)
+		MakeCheckpoint(contractAddress string, oracleSet crosschaintypes.OracleSet, fxBridgeId string) ([32]byte, error)
+		LastBatchNonce(contractAddress string, erc20Address string) (uint64, error)
+		BalanceOf(tokenAddress, owner string) (*big.Int, error)
+		GetTokenStatus(contractAddress, tokenAddress string) (bool, bool, bool, error)
+		GetBridgeTokenList(contractAddress string) ([]contract.FxBridgeToken, error)
+	}{tronClient, httpClient} {
+		nonce, err := state.StateLastOracleSetNonce(testBridgeAddr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), nonce)
+
+		lastCheckpoint, err := state.StateLastOracleSetCheckpoint(testBridgeAddr)
+		require.NoError(t, err)
+		require.Equal(t, [32]byte(checkpoint), lastCheckpoint)
+
+		powerThreshold, err := state.StatePowerThreshold(testBridgeAddr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1870887754), powerThreshold)
+
+		oracleSet := crosschaintypes.OracleSet{
+			Nonce:   1,
+			Members: crosschaintypes.BridgeValidators{{Power: 100, ExternalAddress: "TFysCB929XGezbnyumoFScyevjDggu3BPq"}},
+		}
+		madeCheckpoint, err := state.MakeCheckpoint(testBridgeAddr, oracleSet, "tron")
+		require.NoError(t, err)
+		require.Equal(t, [32]byte(checkpoint), madeCheckpoint)
+
+		batchNonce, err := state.LastBatchNonce(testBridgeAddr, tokenAddr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), batchNonce)
+
+		balance, err := state.BalanceOf(tokenAddr, testBridgeAddr)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(1000), balance)
+
+		isOriginated, isActive, isExist, err := state.GetTokenStatus(testBridgeAddr, tokenAddr)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false, true, true}, []bool{isOriginated, isActive, isExist})
+
+		bridgeTokenList, err := state.GetBridgeTokenList(testBridgeAddr)
+		require.NoError(t, err)
+		require.Equal(t, tokens, bridgeTokenList)
+	}
+}
+
+func TestTronHttpOfflineTx(t *testing.T) {
+	fakeTron := testutil.NewFakeTron(t)
+	httpClient, err := NewTronHttpClient(fakeTron.ServeHttp(t), nil)
+	require.NoError(t, err)
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := address.PubkeyToAddress(privKey.PublicKey).String()
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("pause()")))
+
+	data, err := PackBridgeCall("pause")
+	require.NoError(t, err)
+	offlineTx, err := httpClient.BuildOfflineTx(owner, testBridgeAddr, data, 100000000, 0, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "pause()", offlineTx.Call)
+	require.Equal(t, owner, offlineTx.Owner)
+	require.Equal(t, int64(100000000), offlineTx.FeeLimit)
+	_, err = httpClient.BroadcastOfflineTx(offlineTx, time.Second)
+	require.ErrorContains(t, err, "is not signed")
+
+	// a key outside the owner permission
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, offlineTx.Sign(otherKey))
+	_, err = httpClient.CheckPermission(offlineTx)
+	require.ErrorContains(t, err, "is not a key of permission")
+
+	offlineTx, err = httpClient.BuildOfflineTx(owner, testBridgeAddr, data, 0, 0, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, offlineTx.Sign(privKey))
+	weight, err := httpClient.CheckPermission(offlineTx)
+	require.NoError(t, err)
+	require.True(t, weight.Sufficient())
+	info, err := httpClient.BroadcastOfflineTx(offlineTx, 10*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, offlineTx.TxId, hex.EncodeToString(info.Id))
+	require.Len(t, fakeTron.Transactions(), 1)
+
+	require.NoError(t, fakeTron.SetConstantRevert(testBridgeAddr, testutil.Selector("pause()"), "Pausable: paused"))
+	offlineTx, err = httpClient.BuildOfflineTx(owner, testBridgeAddr, data, 100000000, 0, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, offlineTx.Sign(privKey))
+	_, err = httpClient.BroadcastOfflineTx(offlineTx, 10*time.Second)
+	require.ErrorContains(t, err, "Pausable: paused")
+}
@@ -0,0 +1,435 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+// OfflineTx is the file exchanged between the online machine that builds and broadcasts a transaction
+// and the air-gapped machine that signs it. Only RawData and Signatures are used, the other fields are
+// decoded from RawData for the reviewers and checked again when the file is read.
+type OfflineTx struct {
+	Contract     string    `json:"contract"`
+	Owner        string    `json:"owner"`
+	PermissionId int32     `json:"permission_id"`
+	Call         string    `json:"call"`
+	FeeLimit     int64     `json:"fee_limit"`
+	Expiration   time.Time `json:"expiration"`
+	TxId         string    `json:"txid"`
+	RawData      string    `json:"raw_data"`
+	Signatures   []string  `json:"signatures"`
+}
+
+// BuildOfflineTx builds an unsigned transaction calling data on contractAddress from owner, signed under
+// the owner permission when permissionId is 0 or one of its active permissions. The node sets the ref block,
+// the expiration is moved to expiration from now unless it is 0.
+func (c *TronClient) BuildOfflineTx(owner, contractAddress string, data []byte, feeLimit int64, permissionId int32, expiration time.Duration) (*OfflineTx, error) {
+	ownerAddr, err := address.Base58ToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if feeLimit <= 0 {
+		energy, err := c.EstimateGas(ownerAddr.Bytes(), contractAddr.Bytes(), data)
+		if err != nil {
+			return nil, err
+		}
+		gasPrice, err := c.SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		feeLimit = GetLimit(gasPrice, energy)
+	}
+	tx, err := c.TriggerContract(&troncontract.TriggerSmartContract{
+		OwnerAddress:    ownerAddr.Bytes(),
+		ContractAddress: contractAddr.Bytes(),
+		Data:            data,
+	}, feeLimit)
+	if err != nil {
+		return nil, err
+	}
+	tx.Transaction.RawData.Contract[0].PermissionId = permissionId
+	if expiration > 0 {
+		tx.Transaction.RawData.Expiration = time.Now().Add(expiration).UnixMilli()
+	}
+	return NewOfflineTx(tx.Transaction)
+}
+
+func NewOfflineTx(tx *core.Transaction) (*OfflineTx, error) {
+	rawData, err := proto.Marshal(tx.GetRawData())
+	if err != nil {
+		return nil, err
+	}
+	offlineTx := &OfflineTx{RawData: hex.EncodeToString(rawData), Signatures: make([]string, 0, len(tx.Signature))}
+	for _, signature := range tx.Signature {
+		offlineTx.Signatures = append(offlineTx.Signatures, hex.EncodeToString(signature))
+	}
+	if err = offlineTx.decode(tx); err != nil {
+		return nil, err
+	}
+	return offlineTx, nil
+}
+
+// ReadOfflineTx reads an offline transaction file, the decoded fields are rebuilt from the raw data
+// so that an edited description can not hide the real call.
+func ReadOfflineTx(path string) (*OfflineTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var offlineTx OfflineTx
+	if err = json.Unmarshal(data, &offlineTx); err != nil {
+		return nil, err
+	}
+	tx, err := offlineTx.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	return NewOfflineTx(tx)
+}
+
+func (o *OfflineTx) Write(path string) error {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+func (o *OfflineTx) Transaction() (*core.Transaction, error) {
+	rawData, err := hex.DecodeString(o.RawData)
+	if err != nil {
+		return nil, err
+	}
+	tx := &core.Transaction{RawData: new(core.TransactionRaw)}
+	if err = proto.Unmarshal(rawData, tx.RawData); err != nil {
+		return nil, err
+	}
+	for _, signature := range o.Signatures {
+		sign, err := hex.DecodeString(signature)
+		if err != nil {
+			return nil, err
+		}
+		tx.Signature = append(tx.Signature, sign)
+	}
+	return tx, nil
+}
+
+// Sign adds the signatures of privKeys, the keys must belong to the permission of the transaction,
+// which is only checked against the account when broadcasting.
+func (o *OfflineTx) Sign(privKeys ...*ecdsa.PrivateKey) error {
+	signers, err := o.Signers()
+	if err != nil {
+		return err
+	}
+	tx, err := o.Transaction()
+	if err != nil {
+		return err
+	}
+	for _, privKey := range privKeys {
+		signer := address.PubkeyToAddress(privKey.PublicKey).String()
+		for _, s := range signers {
+			if s == signer {
+				return fmt.Errorf("transaction %s is already signed by %s", o.TxId, signer)
+			}
+		}
+		if err = SignTransaction(tx, privKey); err != nil {
+			return err
+		}
+		signers = append(signers, signer)
+	}
+	signed, err := NewOfflineTx(tx)
+	if err != nil {
+		return err
+	}
+	*o = *signed
+	return nil
+}
+
+// Merge adds the signatures of partially signed copies of the same transaction, a signer is only counted once.
+func (o *OfflineTx) Merge(others ...*OfflineTx) error {
+	signers, err := o.Signers()
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.TxId != o.TxId {
+			return fmt.Errorf("can not merge transaction %s into %s", other.TxId, o.TxId)
+		}
+		otherSigners, err := other.Signers()
+		if err != nil {
+			return err
+		}
+		for i, signer := range otherSigners {
+			duplicate := false
+			for _, s := range signers {
+				if s == signer {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				o.Signatures = append(o.Signatures, other.Signatures[i])
+				signers = append(signers, signer)
+			}
+		}
+	}
+	return nil
+}
+
+// Signers recovers the tron address of every signature.
+func (o *OfflineTx) Signers() ([]string, error) {
+	txId, err := hex.DecodeString(o.TxId)
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]string, 0, len(o.Signatures))
+	for _, signature := range o.Signatures {
+		sign, err := hex.DecodeString(signature)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := crypto.SigToPub(txId, sign)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, address.PubkeyToAddress(*pubKey).String())
+	}
+	return signers, nil
+}
+
+// BroadcastOfflineTx broadcasts a signed offline transaction and waits up to timeout for its receipt.
+func (c *TronClient) BroadcastOfflineTx(offlineTx *OfflineTx, timeout time.Duration) (*core.TransactionInfo, error) {
+	tx, txId, err := offlineTx.broadcastable(c.CheckPermission)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.BroadcastTx(&api.TransactionExtention{Transaction: tx, Txid: txId}); err != nil {
+		return nil, err
+	}
+	return c.waitReceipt(txId, timeout)
+}
+
+// broadcastable returns the signed transaction and its id once the signatures reach the permission threshold.
+func (o *OfflineTx) broadcastable(checkPermission func(*OfflineTx) (*PermissionWeight, error)) (*core.Transaction, []byte, error) {
+	if len(o.Signatures) <= 0 {
+		return nil, nil, fmt.Errorf("transaction %s is not signed", o.TxId)
+	}
+	if time.Now().After(o.Expiration) {
+		return nil, nil, fmt.Errorf("transaction %s expired at %s", o.TxId, o.Expiration)
+	}
+	weight, err := checkPermission(o)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !weight.Sufficient() {
+		return nil, nil, fmt.Errorf("transaction %s signature weight %d is below the threshold %d of permission %d %s",
+			o.TxId, weight.Weight, weight.Threshold, weight.Id, weight.Name)
+	}
+	tx, err := o.Transaction()
+	if err != nil {
+		return nil, nil, err
+	}
+	txId, err := hex.DecodeString(o.TxId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, txId, nil
+}
+
+// waitReceipt waits up to timeout for the receipt of txId, a failed transaction returns the revert reason.
+func (c *TronClient) waitReceipt(txId []byte, timeout time.Duration) (*core.TransactionInfo, error) {
+	info, err := c.WithMint(txId, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("wait transaction %s: %w", hex.EncodeToString(txId), err)
+	}
+	return info, receiptError(txId, info)
+}
+
+func receiptError(txId []byte, info *core.TransactionInfo) error {
+	if info.GetReceipt().GetResult() != core.Transaction_Result_SUCCESS {
+		var contractResult []byte
+		if len(info.GetContractResult()) > 0 {
+			contractResult = info.GetContractResult()[0]
+		}
+		return fmt.Errorf("transaction %s failed: %s, %s", hex.EncodeToString(txId), info.GetReceipt().GetResult(), RevertReason(contractResult))
+	}
+	return nil
+}
+
+func (o *OfflineTx) decode(tx *core.Transaction) error {
+	rawData := tx.GetRawData()
+	if len(rawData.GetContract()) != 1 || rawData.Contract[0].Type != core.Transaction_Contract_TriggerSmartContract {
+		return fmt.Errorf("expect one TriggerSmartContract in the transaction")
+	}
+	trigger := new(troncontract.TriggerSmartContract)
+	if err := rawData.Contract[0].GetParameter().UnmarshalTo(trigger); err != nil {
+		return err
+	}
+	call, err := DecodeBridgeCall(trigger.Data)
+	if err != nil {
+		// calls to other contracts, e.g. token approvals, are shown as raw call data
+		call = "0x" + hex.EncodeToString(trigger.Data)
+	}
+	rawBytes, err := proto.Marshal(rawData)
+	if err != nil {
+		return err
+	}
+	txId := sha256.Sum256(rawBytes)
+
+	o.Contract = address.Address(trigger.ContractAddress).String()
+	o.Owner = address.Address(trigger.OwnerAddress).String()
+	o.PermissionId = rawData.Contract[0].PermissionId
+	o.Call = call
+	o.FeeLimit = rawData.FeeLimit
+	o.Expiration = time.UnixMilli(rawData.Expiration).UTC()
+	o.TxId = hex.EncodeToString(txId[:])
+	return nil
+}
+
+// DecodeBridgeCall formats FxBridgeTron call data as method(name: value, ...), tron addresses are base58.
+func DecodeBridgeCall(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("call data is too short")
+	}
+	method, err := fxBridgeAbi.MethodById(data[:4])
+	if err != nil {
+		return "", err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", err
+	}
+	args := make([]string, 0, len(values))
+	for i, value := range values {
+		args = append(args, fmt.Sprintf("%s: %s", method.Inputs[i].Name, formatAbiValue(value)))
+	}
+	return fmt.Sprintf("%s(%s)", method.Name, strings.Join(args, ", ")), nil
+}
+
+func formatAbiValue(value interface{}) string {
+	switch v := value.(type) {
+	case ethcommon.Address:
+		return contract.AddressToString(v)
+	case []ethcommon.Address:
+		addresses := make([]string, 0, len(v))
+		for _, addr := range v {
+			addresses = append(addresses, contract.AddressToString(addr))
+		}
+		return "[" + strings.Join(addresses, ", ") + "]"
+	case [32]byte:
+		trimmed := strings.TrimRight(string(v[:]), "\x00")
+		for _, r := range trimmed {
+			if !unicode.IsPrint(r) {
+				return "0x" + hex.EncodeToString(v[:])
+			}
+		}
+		return strconv.Quote(trimmed)
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// ParseBridgeArgs converts command line arguments to the input types of a FxBridgeTron method,
+// addresses are base58, bytes32 are strings and arrays are comma separated.
+func ParseBridgeArgs(methodName string, args []string) ([]interface{}, error) {
+	method, ok := fxBridgeAbi.Methods[methodName]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found", methodName)
+	}
+	if len(args) != len(method.Inputs) {
+		return nil, fmt.Errorf("method %s expect %d args, got %d", methodName, len(method.Inputs), len(args))
+	}
+	values := make([]interface{}, 0, len(args))
+	for i, input := range method.Inputs {
+		value, err := parseAbiValue(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("arg %s: %w", input.Name, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func parseAbiValue(abiType ethabi.Type, arg string) (interface{}, error) {
+	switch abiType.T {
+	case ethabi.AddressTy:
+		return contract.StringToAddress(arg)
+	case ethabi.BoolTy:
+		return strconv.ParseBool(arg)
+	case ethabi.StringTy:
+		return arg, nil
+	case ethabi.UintTy:
+		if abiType.Size != 256 {
+			break
+		}
+		value, ok := new(big.Int).SetString(arg, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid uint: %s", arg)
+		}
+		return value, nil
+	case ethabi.FixedBytesTy:
+		if abiType.Size != 32 || len(arg) > 32 {
+			return nil, fmt.Errorf("invalid bytes%d: %s", abiType.Size, arg)
+		}
+		var value [32]byte
+		copy(value[:], arg)
+		return value, nil
+	case ethabi.SliceTy:
+		items := strings.Split(arg, ",")
+		switch abiType.Elem.T {
+		case ethabi.AddressTy:
+			addresses := make([]ethcommon.Address, 0, len(items))
+			for _, item := range items {
+				addr, err := contract.StringToAddress(strings.TrimSpace(item))
+				if err != nil {
+					return nil, err
+				}
+				addresses = append(addresses, addr)
+			}
+			return addresses, nil
+		case ethabi.UintTy:
+			if abiType.Elem.Size != 256 {
+				break
+			}
+			values := make([]*big.Int, 0, len(items))
+			for _, item := range items {
+				value, ok := new(big.Int).SetString(strings.TrimSpace(item), 10)
+				if !ok || value.Sign() < 0 {
+					return nil, fmt.Errorf("invalid uint: %s", item)
+				}
+				values = append(values, value)
+			}
+			return values, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported argument type: %s", abiType.String())
+}
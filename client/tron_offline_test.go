@@ -0,0 +1,126 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func TestOfflineTx(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := address.PubkeyToAddress(privKey.PublicKey).String()
+
+	values, err := ParseBridgeArgs("addBridgeToken", []string{"TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", "transfer/channel-0", "false"})
+	require.NoError(t, err)
+	data, err := PackBridgeCall("addBridgeToken", values...)
+	require.NoError(t, err)
+	offlineTx, err := cli.BuildOfflineTx(owner, testBridgeAddr, data, 100000000, 0, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, `addBridgeToken(_tokenAddr: TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR, _channelIBC: "transfer/channel-0", _isOriginated: false)`, offlineTx.Call)
+	require.Equal(t, owner, offlineTx.Owner)
+	require.Equal(t, testBridgeAddr, offlineTx.Contract)
+	require.Equal(t, int64(100000000), offlineTx.FeeLimit)
+
+	file := filepath.Join(t.TempDir(), "tx.json")
+	require.NoError(t, offlineTx.Write(file))
+	_, err = cli.BroadcastOfflineTx(offlineTx, time.Second)
+	require.Error(t, err, "unsigned transaction")
+
+	// an edited description is rebuilt from the raw data
+	offlineTx.Call = "pause()"
+	require.NoError(t, offlineTx.Write(file))
+	signed, err := ReadOfflineTx(file)
+	require.NoError(t, err)
+	require.Contains(t, signed.Call, "addBridgeToken")
+
+	require.NoError(t, signed.Sign(privKey))
+	require.Error(t, signed.Sign(privKey), "signed twice")
+	require.Len(t, signed.Signatures, 1)
+	require.Equal(t, offlineTx.TxId, signed.TxId)
+
+	info, err := cli.BroadcastOfflineTx(signed, 10*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, signed.TxId, hex.EncodeToString(info.Id))
+	require.Len(t, fakeTron.Transactions(), 1)
+}
+
+func TestParseBridgeArgs(t *testing.T) {
+	_, err := ParseBridgeArgs("pause", []string{"1"})
+	require.Error(t, err)
+	_, err = ParseBridgeArgs("addBridgeToken", []string{"TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", "transfer/channel-0", "no"})
+	require.Error(t, err)
+	_, err = ParseBridgeArgs("unknown", nil)
+	require.Error(t, err)
+	values, err := ParseBridgeArgs("transferOwnership", []string{testBridgeAddr})
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+}
+
+func TestMultiSignOfflineTx(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	ownerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := address.PubkeyToAddress(ownerKey.PublicKey)
+	privKeys := make([]*ecdsa.PrivateKey, 3)
+	keys := make([]*core.Key, 3)
+	for i := range privKeys {
+		privKeys[i], err = crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i] = &core.Key{Address: address.PubkeyToAddress(privKeys[i].PublicKey).Bytes(), Weight: 1}
+	}
+	operations := make([]byte, 32)
+	operations[core.Transaction_Contract_TriggerSmartContract/8] |= 1 << (core.Transaction_Contract_TriggerSmartContract % 8)
+	fakeTron.SetAccount(&core.Account{
+		Address:         owner.Bytes(),
+		OwnerPermission: &core.Permission{Type: core.Permission_Owner, PermissionName: "owner", Threshold: 1, Keys: []*core.Key{{Address: owner.Bytes(), Weight: 1}}},
+		ActivePermission: []*core.Permission{
+			{Type: core.Permission_Active, Id: 2, PermissionName: "admin", Threshold: 2, Operations: operations, Keys: keys},
+			{Type: core.Permission_Active, Id: 3, PermissionName: "transfer", Threshold: 1, Operations: make([]byte, 32), Keys: keys},
+		},
+	})
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("pause()")))
+	data, err := PackBridgeCall("pause")
+	require.NoError(t, err)
+
+	offlineTx, err := cli.BuildOfflineTx(owner.String(), testBridgeAddr, data, 0, 2, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), offlineTx.PermissionId)
+
+	// two signers sign their own copy of the file
+	first, second := *offlineTx, *offlineTx
+	require.NoError(t, first.Sign(privKeys[0]))
+	_, err = cli.BroadcastOfflineTx(&first, time.Second)
+	require.EqualError(t, err, fmt.Sprintf("transaction %s signature weight 1 is below the threshold 2 of permission 2 admin", offlineTx.TxId))
+	require.NoError(t, second.Sign(privKeys[2]))
+	require.NoError(t, first.Merge(&second, &second))
+	require.Len(t, first.Signatures, 2)
+
+	weight, err := cli.CheckPermission(&first)
+	require.NoError(t, err)
+	require.True(t, weight.Sufficient())
+	require.Equal(t, []string{address.Address(keys[0].Address).String(), address.Address(keys[2].Address).String()}, weight.Signers)
+	_, err = cli.BroadcastOfflineTx(&first, 10*time.Second)
+	require.NoError(t, err)
+
+	outsider, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = cli.MultiSignTransact(owner.String(), 2, []*ecdsa.PrivateKey{privKeys[1], outsider}, testBridgeAddr, data, time.Second)
+	require.ErrorContains(t, err, "is not a key of permission 2 admin")
+	_, err = cli.MultiSignTransact(owner.String(), 3, privKeys, testBridgeAddr, data, time.Second)
+	require.EqualError(t, err, "permission 3 transfer does not allow TriggerSmartContract")
+	_, err = cli.MultiSignTransact(owner.String(), 2, privKeys[1:], testBridgeAddr, data, 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, fakeTron.Transactions(), 2)
+}
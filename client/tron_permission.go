@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+)
+
+// PermissionWeight is the weight the signatures of a transaction carry in the permission it is signed under.
+type PermissionWeight struct {
+	Id        int32
+	Name      string
+	Threshold int64
+	Weight    int64
+	Signers   []string
+}
+
+func (w *PermissionWeight) Sufficient() bool {
+	return w.Weight >= w.Threshold
+}
+
+// CheckPermission reads the permission of the transaction from the owner account and sums the weight of
+// its signers, a signer outside the permission keys makes the node reject the transaction.
+func (c *TronClient) CheckPermission(offlineTx *OfflineTx) (*PermissionWeight, error) {
+	account, err := c.GetAccount(offlineTx.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("get account %s: %w", offlineTx.Owner, err)
+	}
+	return permissionWeight(account, offlineTx)
+}
+
+// permissionWeight sums the weight of the signers of offlineTx in its permission of account.
+func permissionWeight(account *core.Account, offlineTx *OfflineTx) (*PermissionWeight, error) {
+	permission, err := accountPermission(account, offlineTx.PermissionId)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := offlineTx.Signers()
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[string]int64, len(permission.Keys))
+	for _, key := range permission.Keys {
+		weights[address.Address(key.Address).String()] = key.Weight
+	}
+	weight := &PermissionWeight{Id: permission.Id, Name: permission.PermissionName, Threshold: permission.Threshold, Signers: signers}
+	for _, signer := range signers {
+		signerWeight, ok := weights[signer]
+		if !ok {
+			return nil, fmt.Errorf("signer %s is not a key of permission %d %s", signer, permission.Id, permission.PermissionName)
+		}
+		weight.Weight += signerWeight
+	}
+	return weight, nil
+}
+
+// accountPermission returns the owner permission for id 0 or the active permission with id, an active
+// permission must allow TriggerSmartContract in its operations.
+func accountPermission(account *core.Account, id int32) (*core.Permission, error) {
+	if id == 0 {
+		if account.OwnerPermission == nil {
+			return nil, fmt.Errorf("account %s has no owner permission", address.Address(account.Address).String())
+		}
+		return account.OwnerPermission, nil
+	}
+	for _, permission := range account.ActivePermission {
+		if permission.Id != id {
+			continue
+		}
+		operation := int(core.Transaction_Contract_TriggerSmartContract)
+		if len(permission.Operations) <= operation/8 || permission.Operations[operation/8]&(1<<(operation%8)) == 0 {
+			return nil, fmt.Errorf("permission %d %s does not allow TriggerSmartContract", permission.Id, permission.PermissionName)
+		}
+		return permission, nil
+	}
+	return nil, fmt.Errorf("account %s has no active permission %d", address.Address(account.Address).String(), id)
+}
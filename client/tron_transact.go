@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+	"google.golang.org/protobuf/proto"
+)
+
+// PackBridgeCall packs the call data of a FxBridgeTron method.
+func PackBridgeCall(method string, args ...interface{}) ([]byte, error) {
+	return fxBridgeAbi.Pack(method, args...)
+}
+
+// SignTransaction appends the signature of privKey over the sha256 of the transaction raw data.
+func SignTransaction(tx *core.Transaction, privKey *ecdsa.PrivateKey) error {
+	rawData, err := proto.Marshal(tx.GetRawData())
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(rawData)
+	signature, err := crypto.Sign(hash[:], privKey)
+	if err != nil {
+		return err
+	}
+	tx.Signature = append(tx.Signature, signature)
+	return nil
+}
+
+// CallContract runs data against contractAddress as a constant call from owner, nothing is broadcast.
+// A reverted call returns an error with the revert reason.
+func (c *TronClient) CallContract(owner, contractAddress string, data []byte) ([]byte, error) {
+	ownerAddr, err := address.Base58ToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	transactionExtention, err := c.Client.TriggerConstantContract(context.Background(), &troncontract.TriggerSmartContract{
+		OwnerAddress:    ownerAddr.Bytes(),
+		ContractAddress: contractAddr.Bytes(),
+		Data:            data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []byte
+	if len(transactionExtention.ConstantResult) > 0 {
+		result = transactionExtention.ConstantResult[0]
+	}
+	if transactionExtention.Result != nil && transactionExtention.Result.Code != 0 {
+		return nil, fmt.Errorf("call reverted: %s", string(transactionExtention.Result.Message))
+	}
+	if transactionExtention.Transaction != nil {
+		for _, ret := range transactionExtention.Transaction.Ret {
+			// misspell ignore
+			if ret.Ret != core.Transaction_Result_SUCESS || ret.ContractRet == core.Transaction_Result_REVERT {
+				return nil, fmt.Errorf("call reverted: %s", RevertReason(result))
+			}
+		}
+	}
+	return result, nil
+}
+
+// Transact triggers data on contractAddress from the address of privKey with a fee limit sized from the
+// estimated energy, signs the transaction, broadcasts it and waits up to timeout for its receipt.
+func (c *TronClient) Transact(privKey *ecdsa.PrivateKey, contractAddress string, data []byte, timeout time.Duration) (*core.TransactionInfo, error) {
+	owner := address.PubkeyToAddress(privKey.PublicKey).String()
+	return c.MultiSignTransact(owner, 0, []*ecdsa.PrivateKey{privKey}, contractAddress, data, timeout)
+}
+
+// MultiSignTransact is Transact for an owner account whose permission permissionId needs the signatures of
+// several keys, the combined weight of privKeys is checked against the permission threshold before broadcasting.
+func (c *TronClient) MultiSignTransact(owner string, permissionId int32, privKeys []*ecdsa.PrivateKey, contractAddress string, data []byte, timeout time.Duration) (*core.TransactionInfo, error) {
+	offlineTx, err := c.BuildOfflineTx(owner, contractAddress, data, 0, permissionId, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = offlineTx.Sign(privKeys...); err != nil {
+		return nil, err
+	}
+	return c.BroadcastOfflineTx(offlineTx, timeout)
+}
+
+// RevertReason decodes the Error(string) revert data of a contract call, other data is returned as hex.
+func RevertReason(data []byte) string {
+	if len(data) <= 0 {
+		return "no revert reason"
+	}
+	reason, err := ethabi.UnpackRevert(data)
+	if err != nil {
+		return hex.EncodeToString(data)
+	}
+	return reason
+}
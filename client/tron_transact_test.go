@@ -0,0 +1,47 @@
+package client
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+func TestTransact(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, fakeTron.SetConstantResult(testBridgeAddr, testutil.Selector("pause()")))
+
+	data, err := PackBridgeCall("pause")
+	require.NoError(t, err)
+	info, err := cli.Transact(privKey, testBridgeAddr, data, 10*time.Second)
+	require.NoError(t, err)
+
+	transactions := fakeTron.Transactions()
+	require.Len(t, transactions, 1)
+	require.Len(t, transactions[0].Signature, 1)
+	rawData, err := proto.Marshal(transactions[0].RawData)
+	require.NoError(t, err)
+	hash := sha256.Sum256(rawData)
+	require.Equal(t, hash[:], info.Id)
+	pubKey, err := crypto.SigToPub(hash[:], transactions[0].Signature[0])
+	require.NoError(t, err)
+	require.Equal(t, address.PubkeyToAddress(privKey.PublicKey), address.PubkeyToAddress(*pubKey))
+}
+
+func TestCallContractRevertReason(t *testing.T) {
+	cli, fakeTron := NewTestTronClient(t)
+	require.NoError(t, fakeTron.SetConstantRevert(testBridgeAddr, testutil.Selector("unpause()"), "Pausable: not paused"))
+
+	data, err := PackBridgeCall("unpause")
+	require.NoError(t, err)
+	_, err = cli.CallContract("TFysCB929XGezbnyumoFScyevjDggu3BPq", testBridgeAddr, data)
+	require.EqualError(t, err, "call reverted: Pausable: not paused")
+}
@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/go-zeromq/zmq4"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+)
+
+const (
+	// ZmqBlockTopic and ZmqTransactionTopic are the trigger names the java-tron event plugin publishes every
+	// block and every transaction under.
+	ZmqBlockTopic       = "blockTrigger"
+	ZmqTransactionTopic = "transactionTrigger"
+
+	zmqReconnectInterval = 3 * time.Second
+	// zmqBufferBlocks is about one hour of tron blocks, older blocks are polled
+	zmqBufferBlocks = 1200
+)
+
+// BlockEventSource reads the bridge events of a block by polling the node, TronClient and TronHttpClient.
+type BlockEventSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	QueryBlockEvent(contractAddress string, blockNumber uint64) ([]contract.IEvent, error)
+	QueryOracleSetUpdatedEvent(contractAddress string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error)
+}
+
+// TronZmqClient takes the bridge events pushed by the java-tron event plugin over ZeroMQ instead of polling
+// every block. ZeroMQ drops messages silently, so a block is only read from the buffer once its block trigger
+// arrived and the transaction trigger of every transaction it lists was received with its logs, the plugin
+// must run with the block and transaction triggers enabled and ethCompatible set. Any other block is polled
+// from the fallback: blocks before the subscription or while it was down, blocks with a dropped trigger,
+// blocks seen with two hashes, blocks not solidified yet and transactions without their log list.
+//
+// The oracle reads blocks TronBlockDelay behind the head, which are solidified already, so the subscription
+// saves the GetTransactionInfoByBlockNum call of every block rather than latency.
+type TronZmqClient struct {
+	endpoint        string
+	contractAddress string
+	fallback        BlockEventSource
+
+	mu          sync.Mutex
+	connected   bool
+	coveredFrom uint64
+	solidified  uint64
+	blocks      map[uint64]*zmqBlock
+}
+
+type zmqBlock struct {
+	hash string
+	// transactions is the transaction list of the block trigger, nil until it arrived
+	transactions []string
+	received     map[string]bool
+	// logs holds the transactions that emitted a log of the bridge contract
+	logs      map[string]*core.TransactionInfo
+	untrusted bool
+}
+
+// zmqTrigger is the json of the block and transaction triggers.
+type zmqTrigger struct {
+	BlockHash                   string   `json:"blockHash"`
+	BlockNumber                 uint64   `json:"blockNumber"`
+	TransactionSize             int      `json:"transactionSize"`
+	TransactionList             []string `json:"transactionList"`
+	LatestSolidifiedBlockNumber uint64   `json:"latestSolidifiedBlockNumber"`
+	TransactionId               string   `json:"transactionId"`
+	// LogList is only sent with ethCompatible, a missing list can not tell the transaction has no log
+	LogList *[]zmqLog `json:"logList"`
+}
+
+type zmqLog struct {
+	Address   string   `json:"address"`
+	TopicList []string `json:"topicList"`
+	Data      string   `json:"data"`
+	Removed   bool     `json:"removed"`
+}
+
+// NewTronZmqClient subscribes to the event plugin at endpoint, e.g. tcp://127.0.0.1:5555, for the events of
+// contractAddress once Run is called.
+func NewTronZmqClient(endpoint, contractAddress string, fallback BlockEventSource) *TronZmqClient {
+	return &TronZmqClient{
+		endpoint:        endpoint,
+		contractAddress: contractAddress,
+		fallback:        fallback,
+		blocks:          make(map[uint64]*zmqBlock),
+	}
+}
+
+// Run keeps the subscription up until ctx is done, reconnecting after any error.
+func (c *TronZmqClient) Run(ctx context.Context) {
+	for {
+		err := c.subscribe(ctx)
+		c.mu.Lock()
+		c.connected = false
+		c.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warnw("tron zmq subscription down, polling blocks", "endpoint", c.endpoint, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(zmqReconnectInterval):
+		}
+	}
+}
+
+func (c *TronZmqClient) subscribe(ctx context.Context) error {
+	sub := zmq4.NewSub(ctx)
+	defer sub.Close()
+	if err := sub.Dial(c.endpoint); err != nil {
+		return err
+	}
+	for _, topic := range []string{ZmqBlockTopic, ZmqTransactionTopic} {
+		if err := sub.SetOption(zmq4.OptionSubscribe, topic); err != nil {
+			return err
+		}
+	}
+	latestBlockNumber, err := c.fallback.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	// the subscription reaches the node after the dial, one more block covers the logs sent meanwhile
+	c.connected, c.coveredFrom, c.blocks = true, latestBlockNumber+2, make(map[uint64]*zmqBlock)
+	c.mu.Unlock()
+	logger.Infow("tron zmq subscribed", "endpoint", c.endpoint, "covered_from", latestBlockNumber+2)
+
+	for {
+		msg, err := sub.Recv()
+		if err != nil {
+			return err
+		}
+		if len(msg.Frames) < 2 {
+			continue
+		}
+		var trigger zmqTrigger
+		if err = json.Unmarshal(msg.Frames[1], &trigger); err != nil {
+			logger.Warnw("tron zmq invalid trigger", "topic", string(msg.Frames[0]), "error", err)
+			continue
+		}
+		c.handleTrigger(string(msg.Frames[0]), trigger)
+	}
+}
+
+func (c *TronZmqClient) handleTrigger(topic string, trigger zmqTrigger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if trigger.LatestSolidifiedBlockNumber > c.solidified {
+		c.solidified = trigger.LatestSolidifiedBlockNumber
+	}
+	if trigger.BlockNumber > zmqBufferBlocks && trigger.BlockNumber-zmqBufferBlocks > c.coveredFrom {
+		c.coveredFrom = trigger.BlockNumber - zmqBufferBlocks
+		for blockNumber := range c.blocks {
+			if blockNumber < c.coveredFrom {
+				delete(c.blocks, blockNumber)
+			}
+		}
+	}
+	if trigger.BlockNumber < c.coveredFrom {
+		return
+	}
+	block, ok := c.blocks[trigger.BlockNumber]
+	if !ok {
+		block = &zmqBlock{received: make(map[string]bool), logs: make(map[string]*core.TransactionInfo)}
+		c.blocks[trigger.BlockNumber] = block
+	}
+	if len(block.hash) <= 0 {
+		block.hash = trigger.BlockHash
+	} else if trigger.BlockHash != block.hash {
+		logger.Warnw("tron zmq block hash changed, polling block", "block_number", trigger.BlockNumber, "block_hash", trigger.BlockHash, "prev_block_hash", block.hash)
+		block.untrusted = true
+		return
+	}
+	switch topic {
+	case ZmqBlockTopic:
+		if len(trigger.TransactionList) != trigger.TransactionSize {
+			logger.Warnw("tron zmq block transaction list incomplete", "block_number", trigger.BlockNumber, "transaction_size", trigger.TransactionSize, "transaction_list", len(trigger.TransactionList))
+			block.untrusted = true
+			return
+		}
+		block.transactions = append(make([]string, 0, len(trigger.TransactionList)), trigger.TransactionList...)
+	case ZmqTransactionTopic:
+		if trigger.LogList == nil {
+			logger.Warnw("tron zmq transaction trigger without log list, enable ethCompatible", "block_number", trigger.BlockNumber, "transaction_id", trigger.TransactionId)
+			block.untrusted = true
+			return
+		}
+		block.received[trigger.TransactionId] = true
+		transactionInfo, err := trigger.toTransactionInfo(c.contractAddress)
+		if err != nil {
+			logger.Warnw("tron zmq invalid transaction trigger", "block_number", trigger.BlockNumber, "transaction_id", trigger.TransactionId, "error", err)
+			block.untrusted = true
+			return
+		}
+		if transactionInfo != nil {
+			block.logs[trigger.TransactionId] = transactionInfo
+		}
+	}
+}
+
+// toTransactionInfo keeps the logs of contractAddress, it returns nil when the transaction emitted none.
+func (trigger zmqTrigger) toTransactionInfo(contractAddress string) (*core.TransactionInfo, error) {
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*core.TransactionInfo_Log, 0)
+	for _, zmqLog := range *trigger.LogList {
+		if zmqLog.Removed {
+			return nil, fmt.Errorf("removed log")
+		}
+		logAddr, err := hex.DecodeString(zmqLog.Address)
+		if err != nil {
+			return nil, err
+		}
+		// the log address is the 20 bytes evm address, without the tron prefix
+		if len(logAddr) == address.AddressLength {
+			logAddr = logAddr[1:]
+		}
+		if !bytes.Equal(logAddr, contractAddr.Bytes()[1:]) {
+			continue
+		}
+		log := &core.TransactionInfo_Log{Address: logAddr}
+		if log.Data, err = hex.DecodeString(zmqLog.Data); err != nil {
+			return nil, err
+		}
+		for _, topic := range zmqLog.TopicList {
+			topicBytes, err := hex.DecodeString(topic)
+			if err != nil {
+				return nil, err
+			}
+			log.Topics = append(log.Topics, topicBytes)
+		}
+		logs = append(logs, log)
+	}
+	if len(logs) <= 0 {
+		return nil, nil
+	}
+	txId, err := hex.DecodeString(trigger.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+	return &core.TransactionInfo{
+		Id:              txId,
+		BlockNumber:     int64(trigger.BlockNumber),
+		ContractAddress: contractAddr.Bytes(),
+		Receipt:         &core.ResourceReceipt{Result: core.Transaction_Result_SUCCESS},
+		Log:             logs,
+	}, nil
+}
+
+// bufferedLogs returns the logs of a block when the subscription received the triggers of all its transactions.
+func (c *TronZmqClient) bufferedLogs(contractAddress string, blockNumber uint64) ([]*core.TransactionInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.connected || contractAddress != c.contractAddress || blockNumber < c.coveredFrom || blockNumber > c.solidified {
+		return nil, false
+	}
+	block, ok := c.blocks[blockNumber]
+	if !ok || block.transactions == nil {
+		logger.Warnw("tron zmq block trigger missing, polling block", "block_number", blockNumber)
+		return nil, false
+	}
+	if block.untrusted {
+		return nil, false
+	}
+	transactionInfos := make([]*core.TransactionInfo, 0, len(block.logs))
+	for _, txId := range block.transactions {
+		if !block.received[txId] {
+			logger.Warnw("tron zmq transaction trigger missing, polling block", "block_number", blockNumber, "transaction_id", txId)
+			return nil, false
+		}
+		if transactionInfo, ok := block.logs[txId]; ok {
+			transactionInfos = append(transactionInfos, transactionInfo)
+		}
+	}
+	return transactionInfos, true
+}
+
+func (c *TronZmqClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.fallback.BlockNumber(ctx)
+}
+
+func (c *TronZmqClient) QueryBlockEvent(contractAddress string, blockNumber uint64) ([]contract.IEvent, error) {
+	if transactionInfos, ok := c.bufferedLogs(contractAddress, blockNumber); ok {
+		return blockEvents(contractAddress, transactionInfos)
+	}
+	return c.fallback.QueryBlockEvent(contractAddress, blockNumber)
+}
+
+func (c *TronZmqClient) QueryOracleSetUpdatedEvent(contractAddress string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error) {
+	if transactionInfos, ok := c.bufferedLogs(contractAddress, blockNumber); ok {
+		return oracleSetUpdatedEvents(contractAddress, transactionInfos)
+	}
+	return c.fallback.QueryOracleSetUpdatedEvent(contractAddress, blockNumber)
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/go-zeromq/zmq4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/testutil"
+)
+
+// countingEventSource records the blocks polled from the fallback.
+type countingEventSource struct {
+	mu          sync.Mutex
+	blockNumber uint64
+	polled      []uint64
+}
+
+func (s *countingEventSource) BlockNumber(context.Context) (uint64, error) {
+	return s.blockNumber, nil
+}
+
+func (s *countingEventSource) QueryBlockEvent(_ string, blockNumber uint64) ([]contract.IEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polled = append(s.polled, blockNumber)
+	return []contract.IEvent{}, nil
+}
+
+func (s *countingEventSource) QueryOracleSetUpdatedEvent(_ string, blockNumber uint64) ([]*contract.FxBridgeTronOracleSetUpdatedEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polled = append(s.polled, blockNumber)
+	return []*contract.FxBridgeTronOracleSetUpdatedEvent{}, nil
+}
+
+func (s *countingEventSource) Polled() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint64{}, s.polled...)
+}
+
+type testZmqPublisher struct {
+	t   *testing.T
+	pub zmq4.Socket
+}
+
+func (p *testZmqPublisher) publish(topic string, trigger map[string]interface{}) {
+	data, err := json.Marshal(trigger)
+	require.NoError(p.t, err)
+	require.NoError(p.t, p.pub.SendMulti(zmq4.NewMsgFrom([]byte(topic), data)))
+}
+
+// bridgeLog is the log list entry of a bridge event as the event plugin sends it with ethCompatible.
+func (p *testZmqPublisher) bridgeLog(eventName string, args ...interface{}) map[string]interface{} {
+	topics, data, err := testutil.PackEventLog(eventName, args...)
+	require.NoError(p.t, err)
+	hexTopics := make([]string, len(topics))
+	for i, logTopic := range topics {
+		hexTopics[i] = hex.EncodeToString(logTopic)
+	}
+	bridgeAddr, err := address.Base58ToAddress(testBridgeAddr)
+	require.NoError(p.t, err)
+	return map[string]interface{}{"address": hex.EncodeToString(bridgeAddr.Bytes()[1:]), "topicList": hexTopics, "data": hex.EncodeToString(data)}
+}
+
+// publishTx sends the transaction trigger of txId, logs nil leaves out the log list like without ethCompatible.
+func (p *testZmqPublisher) publishTx(blockNumber uint64, blockHash, txId string, logs []map[string]interface{}) {
+	trigger := map[string]interface{}{
+		"triggerName":   ZmqTransactionTopic,
+		"transactionId": txId,
+		"blockHash":     blockHash,
+		"blockNumber":   blockNumber,
+	}
+	if logs != nil {
+		trigger["logList"] = logs
+	}
+	p.publish(ZmqTransactionTopic, trigger)
+}
+
+// publishBlock sends the block trigger listing txIds, which moves the solidified block number.
+func (p *testZmqPublisher) publishBlock(blockNumber, solidified uint64, txIds ...string) {
+	p.publish(ZmqBlockTopic, map[string]interface{}{
+		"triggerName":                 ZmqBlockTopic,
+		"blockNumber":                 blockNumber,
+		"blockHash":                   testBlockHash(blockNumber),
+		"transactionSize":             len(txIds),
+		"transactionList":             append([]string{}, txIds...),
+		"latestSolidifiedBlockNumber": solidified,
+	})
+}
+
+func testBlockHash(blockNumber uint64) string {
+	return fmt.Sprintf("%064x", blockNumber)
+}
+
+func newTestTronZmqClient(t *testing.T, fallback BlockEventSource) (*TronZmqClient, *testZmqPublisher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	pub := zmq4.NewPub(ctx)
+	t.Cleanup(func() { _ = pub.Close() })
+	require.NoError(t, pub.Listen("tcp://127.0.0.1:0"))
+	publisher := &testZmqPublisher{t: t, pub: pub}
+
+	zmqClient := NewTronZmqClient("tcp://"+pub.Addr().String(), testBridgeAddr, fallback)
+	go zmqClient.Run(ctx)
+	// a sub socket only receives once its subscription reached the publisher
+	require.Eventually(t, func() bool {
+		publisher.publishBlock(1, 1)
+		zmqClient.mu.Lock()
+		defer zmqClient.mu.Unlock()
+		return zmqClient.solidified == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	return zmqClient, publisher
+}
+
+func waitSolidified(t *testing.T, zmqClient *TronZmqClient, publisher *testZmqPublisher, blockNumber, solidified uint64) {
+	publisher.publishBlock(blockNumber, solidified)
+	require.Eventually(t, func() bool {
+		zmqClient.mu.Lock()
+		defer zmqClient.mu.Unlock()
+		return zmqClient.solidified == solidified
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTronZmqQueryBlockEvent(t *testing.T) {
+	fallback := &countingEventSource{blockNumber: 100}
+	zmqClient, publisher := newTestTronZmqClient(t, fallback)
+
+	token, sender := ethCommon.HexToAddress("0xa614f803b6fd780986a42c78ec9c7f77e6ded13c"), ethCommon.HexToAddress("0x3e2b9a0c3f35d4a8a1e9c1bf0f7d2b6d4e8f1a23")
+	sendToFx := func(nonce int64) []map[string]interface{} {
+		return []map[string]interface{}{publisher.bridgeLog("SendToFxEvent", token, sender, [32]byte{}, [32]byte{}, big.NewInt(100), big.NewInt(nonce))}
+	}
+	otherLog := map[string]interface{}{"address": "a614f803b6fd780986a42c78ec9c7f77e6ded13c", "topicList": []string{}, "data": ""}
+	tx := func(name string) string { return hex.EncodeToString([]byte(name)) }
+
+	// every transaction of the block arrived
+	publisher.publishTx(105, testBlockHash(105), tx("tx1"), sendToFx(1))
+	publisher.publishTx(105, testBlockHash(105), tx("other"), []map[string]interface{}{otherLog})
+	publisher.publishBlock(105, 0, tx("tx1"), tx("other"))
+	publisher.publishTx(106, testBlockHash(106), tx("tx2"), []map[string]interface{}{publisher.bridgeLog("OracleSetUpdatedEvent",
+		big.NewInt(2), big.NewInt(2), []ethCommon.Address{sender}, []*big.Int{big.NewInt(100)})})
+	publisher.publishBlock(106, 0, tx("tx2"))
+	// the trigger of a transaction with a bridge event was dropped
+	publisher.publishTx(107, testBlockHash(107), tx("tx3"), sendToFx(3))
+	publisher.publishBlock(107, 0, tx("tx3"), tx("tx4"))
+	// the plugin runs without ethCompatible
+	publisher.publishTx(108, testBlockHash(108), tx("tx5"), nil)
+	publisher.publishBlock(108, 0, tx("tx5"))
+	// the block was replaced by a fork
+	publisher.publishTx(109, testBlockHash(1109), tx("tx6"), sendToFx(6))
+	publisher.publishBlock(109, 0, tx("tx6"))
+	// every block trigger arrives but the one of block 104
+	for _, blockNumber := range []uint64{102, 103, 110, 111} {
+		publisher.publishBlock(blockNumber, 0)
+	}
+	waitSolidified(t, zmqClient, publisher, 112, 110)
+
+	events, err := zmqClient.QueryBlockEvent(testBridgeAddr, 105)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(1), events[0].GetEventNonce())
+	require.Equal(t, ethCommon.BytesToHash([]byte("tx1")), events[0].GetTxHash())
+
+	oracleSetUpdatedEvents, err := zmqClient.QueryOracleSetUpdatedEvent(testBridgeAddr, 106)
+	require.NoError(t, err)
+	require.Len(t, oracleSetUpdatedEvents, 1)
+	require.Equal(t, big.NewInt(2), oracleSetUpdatedEvents[0].NewOracleSetNonce)
+
+	for _, blockNumber := range []uint64{103, 110} {
+		events, err = zmqClient.QueryBlockEvent(testBridgeAddr, blockNumber)
+		require.NoError(t, err)
+		require.Len(t, events, 0)
+	}
+	require.Empty(t, fallback.Polled())
+
+	// before the subscription, dropped block trigger, dropped transaction trigger, no log list, fork and not solidified
+	for _, blockNumber := range []uint64{101, 104, 107, 108, 109, 111} {
+		_, err = zmqClient.QueryBlockEvent(testBridgeAddr, blockNumber)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []uint64{101, 104, 107, 108, 109, 111}, fallback.Polled())
+}
+
+func TestTronZmqReconnect(t *testing.T) {
+	fallback := &countingEventSource{blockNumber: 100}
+	zmqClient, publisher := newTestTronZmqClient(t, fallback)
+	waitSolidified(t, zmqClient, publisher, 105, 105)
+	_, err := zmqClient.QueryBlockEvent(testBridgeAddr, 105)
+	require.NoError(t, err)
+	require.Empty(t, fallback.Polled())
+
+	require.NoError(t, publisher.pub.Close())
+	require.Eventually(t, func() bool {
+		zmqClient.mu.Lock()
+		defer zmqClient.mu.Unlock()
+		return !zmqClient.connected
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = zmqClient.QueryBlockEvent(testBridgeAddr, 105)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{105}, fallback.Polled())
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/functionx/fx-tron-bridge/bridge"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+const (
+	outputTable = "table"
+	outputCsv   = "csv"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit the oracle confirmations stored by fx core",
+	}
+	signaturesCmd := &cobra.Command{
+		Use:   "signatures",
+		Short: "Report every oracle set and batch nonce the bridger did not confirm or confirmed with a wrong signature",
+		Long: "Report every oracle set and batch nonce the bridger did not confirm or confirmed with a wrong signature.\n" +
+			"Batches executed on tron are removed from fx core with their confirms, only stored batches are audited,\n" +
+			"the nonces pruned by fx core are reported as not auditable.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output := viper.GetString("output")
+			if output != outputTable && output != outputCsv {
+				return fmt.Errorf("invalid output: %s, expect %s or %s", output, outputTable, outputCsv)
+			}
+			fxBridge, err := newQueryFxTronBridge()
+			if err != nil {
+				return err
+			}
+			auditor, err := bridge.NewSignatureAuditor(fxBridge, viper.GetString("bridger-addr"))
+			if err != nil {
+				return err
+			}
+			fromNonce := viper.GetUint64("from-nonce")
+			findings, err := auditor.AuditOracleSets(fromNonce)
+			if err != nil {
+				return err
+			}
+			batchFindings, err := auditor.AuditBatches(fromNonce)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, batchFindings...)
+
+			if err = writeAuditFindings(cmd, output, findings); err != nil {
+				return err
+			}
+			failures := 0
+			for _, finding := range findings {
+				if finding.Status != bridge.AuditNotAuditable {
+					failures++
+				}
+			}
+			if failures > 0 {
+				return fmt.Errorf("%d confirmations missing or invalid", failures)
+			}
+			return nil
+		},
+	}
+	utils.AddFlags(signaturesCmd, "bridge-addr", "", "tron contract bridge-token address", true)
+	utils.AddFlags(signaturesCmd, "tron-grpc", "", "tron chain node", true)
+	utils.AddFlags(signaturesCmd, "fx-grpc", "", "fx chain node grpc", true)
+	addGrpcFlags(signaturesCmd, "tron", "fx")
+	utils.AddFlags(signaturesCmd, "bridger-addr", "", "fx bridger address of the audited oracle", true)
+	utils.AddFlags(signaturesCmd, "from-nonce", uint64(1), "first oracle set and batch nonce to audit", false)
+	utils.AddFlags(signaturesCmd, "output", outputTable, "output format (table|csv)", false)
+	cmd.AddCommand(signaturesCmd)
+	return cmd
+}
+
+func writeAuditFindings(cmd *cobra.Command, output string, findings []bridge.AuditFinding) error {
+	header := []string{"KIND", "TOKEN", "NONCE", "STATUS", "DETAIL"}
+	if output == outputCsv {
+		writer := csv.NewWriter(cmd.OutOrStdout())
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, finding := range findings {
+			if err := writer.Write([]string{finding.Kind, finding.Token, strconv.FormatUint(finding.Nonce, 10), finding.Status, finding.Detail}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, finding := range findings {
+		token := finding.Token
+		if len(token) <= 0 {
+			token = "-"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\n", finding.Kind, token, finding.Nonce, finding.Status, finding.Detail)
+	}
+	return writer.Flush()
+}
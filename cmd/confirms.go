@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/functionx/fx-tron-bridge/bridge"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+func newConfirmsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "confirms",
+		Short: "Verify the oracle confirmations of a batch or oracle set before it is submitted to tron",
+	}
+	batchCmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Verify the confirmations of an outgoing batch",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fxBridge, err := newQueryFxTronBridge()
+			if err != nil {
+				return err
+			}
+			report, err := fxBridge.VerifyBatchConfirms(viper.GetString("token"), viper.GetUint64("nonce"))
+			if err != nil {
+				return err
+			}
+			return writeConfirmReport(cmd, report)
+		},
+	}
+	oracleSetCmd := &cobra.Command{
+		Use:   "oracle-set",
+		Short: "Verify the confirmations of an oracle set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fxBridge, err := newQueryFxTronBridge()
+			if err != nil {
+				return err
+			}
+			report, err := fxBridge.VerifyOracleSetConfirms(viper.GetUint64("nonce"))
+			if err != nil {
+				return err
+			}
+			return writeConfirmReport(cmd, report)
+		},
+	}
+	for _, subCmd := range []*cobra.Command{batchCmd, oracleSetCmd} {
+		utils.AddFlags(subCmd, "bridge-addr", "", "tron contract bridge-token address", true)
+		utils.AddFlags(subCmd, "tron-grpc", "", "tron chain node", true)
+		utils.AddFlags(subCmd, "fx-grpc", "", "fx chain node grpc", true)
+		addGrpcFlags(subCmd, "tron", "fx")
+		utils.AddFlags(subCmd, "nonce", uint64(0), "batch or oracle set nonce", true)
+	}
+	utils.AddFlags(batchCmd, "token", "", "batch token contract", true)
+	cmd.AddCommand(batchCmd, oracleSetCmd)
+	return cmd
+}
+
+// newQueryFxTronBridge connects to tron and fx core without keys, for commands that only read state.
+func newQueryFxTronBridge() (*bridge.FxTronBridge, error) {
+	return newGrpcFxTronBridge(nil, nil)
+}
+
+func writeConfirmReport(cmd *cobra.Command, report *bridge.ConfirmReport) error {
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "BRIDGER\tEXTERNAL\tSIGNER\tPOWER\tSTATUS")
+	for _, check := range report.Checks {
+		status := "valid"
+		if !check.Valid {
+			status = check.Reason
+		}
+		signer := check.Signer
+		if len(signer) <= 0 {
+			signer = "-"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\n", check.BridgerAddress, check.ExternalAddress, signer, check.Power, status)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%s nonce %d: valid power %d, contract threshold %d\n", report.Kind, report.Nonce, report.ValidPower, report.PowerThreshold)
+	if invalid := report.InvalidCount(); invalid > 0 {
+		return fmt.Errorf("%d invalid signatures from contract oracle set members, submitting them reverts", invalid)
+	}
+	if !report.Sufficient() {
+		return fmt.Errorf("valid power %d does not exceed the contract threshold %d", report.ValidPower, report.PowerThreshold)
+	}
+	return nil
+}
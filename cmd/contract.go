@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/client"
+	"github.com/functionx/fx-tron-bridge/contract"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+const (
+	defaultReceiptTimeout = time.Minute
+	defaultDeployFeeLimit = int64(5000000000) // 5000 trx
+)
+
+func newContractCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contract",
+		Short: "Deploy the FxBridgeTron contract and call its owner functions",
+	}
+	addBridgeTokenCmd := &cobra.Command{
+		Use:   "add-bridge-token <token>",
+		Short: "Add a bridge token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := contract.StringToAddress(args[0])
+			if err != nil {
+				return err
+			}
+			channelIBC := viper.GetString("channel-ibc")
+			if len(channelIBC) > 32 {
+				return fmt.Errorf("channel ibc is longer than 32 bytes: %s", channelIBC)
+			}
+			var channel [32]byte
+			copy(channel[:], channelIBC)
+			return callBridgeContract(cmd, "addBridgeToken", token, channel, viper.GetBool("originated"))
+		},
+	}
+	pauseBridgeTokenCmd := &cobra.Command{
+		Use:   "pause-bridge-token <token>",
+		Short: "Pause the transfers of a bridge token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := contract.StringToAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return callBridgeContract(cmd, "pauseBridgeToken", token)
+		},
+	}
+	activeBridgeTokenCmd := &cobra.Command{
+		Use:   "active-bridge-token <token>",
+		Short: "Resume the transfers of a paused bridge token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := contract.StringToAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return callBridgeContract(cmd, "activeBridgeToken", token)
+		},
+	}
+	pauseCmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause the bridge contract",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return callBridgeContract(cmd, "pause")
+		},
+	}
+	unpauseCmd := &cobra.Command{
+		Use:   "unpause",
+		Short: "Unpause the bridge contract",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return callBridgeContract(cmd, "unpause")
+		},
+	}
+	transferOwnerCmd := &cobra.Command{
+		Use:   "transfer-owner <token> <new-owner>",
+		Short: "Transfer the ownership of a fx core originated token contract",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := contract.StringToAddress(args[0])
+			if err != nil {
+				return err
+			}
+			newOwner, err := contract.StringToAddress(args[1])
+			if err != nil {
+				return err
+			}
+			return callBridgeContract(cmd, "transferOwner", token, newOwner)
+		},
+	}
+	transferOwnershipCmd := &cobra.Command{
+		Use:   "transfer-ownership <new-owner>",
+		Short: "Transfer the ownership of the bridge contract",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			newOwner, err := contract.StringToAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return callBridgeContract(cmd, "transferOwnership", newOwner)
+		},
+	}
+	utils.AddFlags(addBridgeTokenCmd, "channel-ibc", "", "ibc channel of the token on fx core, e.g. transfer/channel-0", false)
+	utils.AddFlags(addBridgeTokenCmd, "originated", false, "the token is originated on fx core and minted by the bridge", false)
+
+	subCmds := []*cobra.Command{addBridgeTokenCmd, pauseBridgeTokenCmd, activeBridgeTokenCmd, pauseCmd, unpauseCmd, transferOwnerCmd, transferOwnershipCmd}
+	for _, subCmd := range subCmds {
+		utils.AddFlags(subCmd, "bridge-addr", "", "tron contract bridge-token address", true)
+		utils.AddFlags(subCmd, "tron-grpc", "", "tron chain node", true)
+		addGrpcFlags(subCmd, "tron")
+		utils.AddFlags(subCmd, "tron-key", "", "tron keys signing for the contract owner, comma separated", true)
+		utils.AddFlags(subCmd, "tron-pwd", "", "tron pwd, or one pwd per tron key comma separated", false)
+		utils.AddFlags(subCmd, "owner", "", "contract owner account, the address of the first tron key when empty", false)
+		utils.AddFlags(subCmd, "permission-id", 0, "permission of the owner account the tron keys sign with, 0 is the owner permission", false)
+		utils.AddFlags(subCmd, "dry-run", false, "only run a constant call and show the revert reason", false)
+		utils.AddFlags(subCmd, "receipt-timeout", defaultReceiptTimeout, "time to wait for the transaction receipt", false)
+	}
+	cmd.AddCommand(append(subCmds, newContractDeployCmd())...)
+	return cmd
+}
+
+func newContractDeployCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy the FxBridgeTron contract and init it with the current fx core oracle set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			crossChainClient, err := newCrossChainClient(context.Background())
+			if err != nil {
+				return err
+			}
+			params, err := crossChainClient.Params(fxtronbridge.Tron)
+			if err != nil {
+				return err
+			}
+			oracleSet, err := crossChainClient.CurrentOracleSet(fxtronbridge.Tron)
+			if err != nil {
+				return err
+			}
+			if oracleSet == nil {
+				return fmt.Errorf("fx core has no current %s oracle set", fxtronbridge.Tron)
+			}
+			initData, err := client.PackBridgeInit(params.GravityId, fxtronbridge.ThresholdVotePower, *oracleSet)
+			if err != nil {
+				return err
+			}
+			privKey, err := utils.DecryptEthPrivateKey(viper.GetString("tron-key"), viper.GetString("tron-pwd"))
+			if err != nil {
+				return err
+			}
+			tronClient, err := newTronClient()
+			if err != nil {
+				return err
+			}
+
+			bridgeAddr, info, err := tronClient.DeployBridgeContract(privKey, viper.GetInt64("fee-limit"), viper.GetDuration("receipt-timeout"))
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "deploy txid: %s, block: %d, contract: %s\n", hex.EncodeToString(info.GetId()), info.GetBlockNumber(), bridgeAddr)
+			info, err = tronClient.Transact(privKey, bridgeAddr, initData, viper.GetDuration("receipt-timeout"))
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "init txid: %s, block: %d, fx bridge id: %s, oracle set nonce: %d, members: %d, power threshold: %d\n",
+				hex.EncodeToString(info.GetId()), info.GetBlockNumber(), params.GravityId, oracleSet.Nonce, len(oracleSet.Members), uint64(fxtronbridge.ThresholdVotePower))
+			if err = tronClient.CheckBridgeInit(bridgeAddr, params.GravityId, *oracleSet); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "contract %s fx bridge id and oracle set checkpoint verified\n", bridgeAddr)
+			return nil
+		},
+	}
+	utils.AddFlags(cmd, "tron-grpc", "", "tron chain node", true)
+	utils.AddFlags(cmd, "fx-grpc", "", "fx chain node grpc", true)
+	addGrpcFlags(cmd, "tron", "fx")
+	utils.AddFlags(cmd, "tron-key", "", "tron key of the deployer, which becomes the contract owner", true)
+	utils.AddFlags(cmd, "tron-pwd", "", "tron pwd", false)
+	utils.AddFlags(cmd, "fee-limit", defaultDeployFeeLimit, "fee limit of the deploy transaction in sun", false)
+	utils.AddFlags(cmd, "receipt-timeout", defaultReceiptTimeout, "time to wait for the transaction receipt", false)
+	return cmd
+}
+
+// callBridgeContract sends a FxBridgeTron method call signed by the tron keys, or only simulates it with --dry-run.
+func callBridgeContract(cmd *cobra.Command, method string, args ...interface{}) error {
+	privKeys, err := decryptTronKeys()
+	if err != nil {
+		return err
+	}
+	tronClient, err := newTronClient()
+	if err != nil {
+		return err
+	}
+	data, err := client.PackBridgeCall(method, args...)
+	if err != nil {
+		return err
+	}
+	bridgeAddr := viper.GetString("bridge-addr")
+	owner := viper.GetString("owner")
+	if len(owner) <= 0 {
+		owner = address.PubkeyToAddress(privKeys[0].PublicKey).String()
+	}
+	if viper.GetBool("dry-run") {
+		if _, err = tronClient.CallContract(owner, bridgeAddr, data); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s from %s succeeds\n", method, owner)
+		return nil
+	}
+	info, err := tronClient.MultiSignTransact(owner, int32(viper.GetInt("permission-id")), privKeys, bridgeAddr, data, viper.GetDuration("receipt-timeout"))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s txid: %s, block: %d, energy: %d, fee: %d\n", method, hex.EncodeToString(info.GetId()),
+		info.GetBlockNumber(), info.GetReceipt().GetEnergyUsageTotal(), info.GetFee())
+	return nil
+}
+
+// decryptTronKeys decrypts the comma separated --tron-key values, --tron-pwd is either shared by all keys
+// or gives one pwd per key.
+func decryptTronKeys() ([]*ecdsa.PrivateKey, error) {
+	keys := utils.SplitFlagValues(viper.GetString("tron-key"))
+	if len(keys) <= 0 {
+		return nil, fmt.Errorf("no tron key")
+	}
+	pwds := utils.SplitFlagValues(viper.GetString("tron-pwd"))
+	privKeys := make([]*ecdsa.PrivateKey, 0, len(keys))
+	for i, key := range keys {
+		pwd := viper.GetString("tron-pwd")
+		if len(pwds) == len(keys) && len(keys) > 1 {
+			pwd = pwds[i]
+		}
+		privKey, err := utils.DecryptEthPrivateKey(key, pwd)
+		if err != nil {
+			return nil, fmt.Errorf("tron key %s: %w", key, err)
+		}
+		privKeys = append(privKeys, privKey)
+	}
+	return privKeys, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/bridge"
+	"github.com/functionx/fx-tron-bridge/client"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+const defaultFollowTimeout = 10 * time.Minute
+
+func newDepositCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "Send tokens from tron to a fx core account through the bridge contract",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, ok := new(big.Int).SetString(viper.GetString("amount"), 10)
+			if !ok || amount.Sign() <= 0 {
+				return fmt.Errorf("invalid amount: %s", viper.GetString("amount"))
+			}
+			receiver, targetIbc := viper.GetString("receiver"), viper.GetString("target-ibc")
+			if _, err := client.FxDestination(receiver); err != nil {
+				return fmt.Errorf("invalid receiver %s: %w", receiver, err)
+			}
+			if _, err := client.TargetIBC(targetIbc); err != nil {
+				return err
+			}
+			var fxBridge *bridge.FxTronBridge
+			if viper.GetBool("follow") {
+				if len(viper.GetString("fx-grpc")) <= 0 {
+					return fmt.Errorf("--follow needs --fx-grpc")
+				}
+				var err error
+				if fxBridge, err = newQueryFxTronBridge(); err != nil {
+					return err
+				}
+			}
+			privKey, err := utils.DecryptEthPrivateKey(viper.GetString("tron-key"), viper.GetString("tron-pwd"))
+			if err != nil {
+				return err
+			}
+			tronClient, err := newTronClient()
+			if err != nil {
+				return err
+			}
+			bridgeAddr, token, timeout := viper.GetString("bridge-addr"), viper.GetString("token"), viper.GetDuration("receipt-timeout")
+
+			info, err := tronClient.ApproveBridge(privKey, token, bridgeAddr, amount, timeout)
+			if err != nil {
+				return fmt.Errorf("approve: %w", err)
+			}
+			if info != nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "approve txid: %s, block: %d\n", hex.EncodeToString(info.GetId()), info.GetBlockNumber())
+			}
+			info, err = tronClient.SendToFx(privKey, bridgeAddr, token, receiver, targetIbc, amount, timeout)
+			if err != nil {
+				return fmt.Errorf("send to fx: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "sendToFx txid: %s, block: %d, energy: %d, fee: %d\n", hex.EncodeToString(info.GetId()),
+				info.GetBlockNumber(), info.GetReceipt().GetEnergyUsageTotal(), info.GetFee())
+			event, err := tronClient.SendToFxEvent(bridgeAddr, info)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "SendToFxEvent nonce: %d, amount: %s, receiver: %s, target ibc: %q\n",
+				event.GetEventNonce(), event.Amount.String(), receiver, targetIbc)
+			if fxBridge == nil {
+				return nil
+			}
+			return followClaim(cmd, fxBridge, event.GetEventNonce(), viper.GetDuration("follow-timeout"))
+		},
+	}
+	utils.AddFlags(cmd, "bridge-addr", "", "tron contract bridge-token address", true)
+	utils.AddFlags(cmd, "tron-grpc", "", "tron chain node", true)
+	addGrpcFlags(cmd, "tron")
+	utils.AddFlags(cmd, "tron-key", "", "tron key of the sender", true)
+	utils.AddFlags(cmd, "tron-pwd", "", "tron pwd", false)
+	utils.AddFlags(cmd, "token", "", "tron token contract address", true)
+	utils.AddFlags(cmd, "amount", "", "amount in the smallest token unit", true)
+	utils.AddFlags(cmd, "receiver", "", "fx core bech32 account receiving the tokens", true)
+	utils.AddFlags(cmd, "target-ibc", "", "ibc target the tokens are forwarded to from fx core, e.g. px/transfer/channel-0", false)
+	utils.AddFlags(cmd, "receipt-timeout", defaultReceiptTimeout, "time to wait for the transaction receipt", false)
+	utils.AddFlags(cmd, "follow", false, "poll fx core until the oracles attest the deposit claim", false)
+	utils.AddFlags(cmd, "fx-grpc", "", "fx chain node grpc, required by --follow", false)
+	addGrpcFlags(cmd, "fx")
+	utils.AddFlags(cmd, "follow-timeout", defaultFollowTimeout, "time to wait for the attestation with --follow", false)
+	return cmd
+}
+
+// followClaim polls the oracle claims of eventNonce every fx block until they hold the attestation power.
+func followClaim(cmd *cobra.Command, fxBridge *bridge.FxTronBridge, eventNonce uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	claimedPower := uint64(0)
+	for {
+		progress, err := fxBridge.ClaimProgress(eventNonce)
+		if err != nil {
+			return err
+		}
+		if progress.ClaimedPower != claimedPower || progress.Attested() {
+			claimedPower = progress.ClaimedPower
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "event nonce %d claimed by %d oracles, power %d/%d\n",
+				eventNonce, len(progress.Claimed), progress.ClaimedPower, progress.TotalPower)
+		}
+		if progress.Attested() {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "event nonce %d attested on fx core\n", eventNonce)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("event nonce %d not attested after %s, pending oracles: %v", eventNonce, timeout, progress.Pending)
+		}
+		time.Sleep(fxtronbridge.FxAvgBlockMillisecond)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/bridge"
+	"github.com/functionx/fx-tron-bridge/client"
+	"github.com/functionx/fx-tron-bridge/fxchain"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+// addGrpcFlags adds the tls and header flags of the chain grpc clients, chain is tron or fx.
+func addGrpcFlags(cmd *cobra.Command, chains ...string) {
+	for _, chain := range chains {
+		utils.AddFlags(cmd, chain+"-grpc-ca", "", chain+" grpc ca file trusted besides the system roots, https urls only", false)
+		utils.AddFlags(cmd, chain+"-grpc-cert", "", chain+" grpc client certificate file for mutual tls", false)
+		utils.AddFlags(cmd, chain+"-grpc-key", "", chain+" grpc client key file for mutual tls", false)
+		utils.AddFlags(cmd, chain+"-grpc-insecure-skip-verify", false, "do not verify the "+chain+" grpc server certificate", false)
+		utils.AddFlags(cmd, chain+"-grpc-header", "", chain+" grpc metadata headers sent with every call, comma separated key=value", false)
+		if chain == "tron" {
+			utils.AddFlags(cmd, "tron-api-key", "", "api key sent as the "+fxtronbridge.TronApiKeyHeader+" header, e.g. for TronGrid", false)
+		}
+	}
+}
+
+func grpcOptions(chain string) (fxtronbridge.GrpcOptions, error) {
+	headers, err := fxtronbridge.ParseGrpcHeaders(utils.SplitFlagValues(viper.GetString(chain + "-grpc-header")))
+	if err != nil {
+		return fxtronbridge.GrpcOptions{}, fmt.Errorf("%s-grpc-header: %w", chain, err)
+	}
+	if chain == "tron" && len(viper.GetString("tron-api-key")) > 0 {
+		headers[fxtronbridge.TronApiKeyHeader] = viper.GetString("tron-api-key")
+	}
+	return fxtronbridge.GrpcOptions{
+		CAFile:             viper.GetString(chain + "-grpc-ca"),
+		CertFile:           viper.GetString(chain + "-grpc-cert"),
+		KeyFile:            viper.GetString(chain + "-grpc-key"),
+		InsecureSkipVerify: viper.GetBool(chain + "-grpc-insecure-skip-verify"),
+		Headers:            headers,
+	}, nil
+}
+
+func newTronClient() (*client.TronClient, error) {
+	options, err := grpcOptions("tron")
+	if err != nil {
+		return nil, err
+	}
+	return client.NewTronGrpcClientWithOptions(viper.GetString("tron-grpc"), options)
+}
+
+// newTronHttpClient connects to the tron http api with the tron grpc headers, e.g. the TronGrid api key.
+func newTronHttpClient() (*client.TronHttpClient, error) {
+	options, err := grpcOptions("tron")
+	if err != nil {
+		return nil, err
+	}
+	return client.NewTronHttpClient(viper.GetString("tron-http-api"), options.Headers)
+}
+
+func newCrossChainClient(ctx context.Context) (*fxchain.CrossChainClient, error) {
+	options, err := grpcOptions("fx")
+	if err != nil {
+		return nil, err
+	}
+	return fxchain.NewCrossChainClientWithOptions(ctx, viper.GetString("fx-grpc"), options)
+}
+
+func newGrpcFxTronBridge(orcPrivKey *secp256k1.PrivKey, tronPrivateKey *ecdsa.PrivateKey) (*bridge.FxTronBridge, error) {
+	tronOptions, err := grpcOptions("tron")
+	if err != nil {
+		return nil, err
+	}
+	fxOptions, err := grpcOptions("fx")
+	if err != nil {
+		return nil, err
+	}
+	return bridge.NewGrpcFxTronBridge(viper.GetString("bridge-addr"), viper.GetString("tron-grpc"), viper.GetString("fx-grpc"),
+		tronOptions, fxOptions, orcPrivKey, tronPrivateKey)
+}
+
+// newFxTronBridge reads the bridge events from tron-http-api when it is set, and the bridge state too when
+// tron-grpc is not set, so a provider without grpc can run the bridge.
+func newFxTronBridge(orcPrivKey *secp256k1.PrivKey, tronPrivateKey *ecdsa.PrivateKey) (*bridge.FxTronBridge, error) {
+	if len(viper.GetString("tron-grpc")) > 0 {
+		fxTronBridge, err := newGrpcFxTronBridge(orcPrivKey, tronPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(viper.GetString("tron-http-api")) > 0 {
+			if fxTronBridge.EventSource, err = newTronHttpClient(); err != nil {
+				return nil, err
+			}
+		}
+		return fxTronBridge, nil
+	}
+	if len(viper.GetString("tron-http-api")) <= 0 {
+		return nil, fmt.Errorf("tron-grpc or tron-http-api is required")
+	}
+	tronHttpClient, err := newTronHttpClient()
+	if err != nil {
+		return nil, err
+	}
+	crossChainClient, err := newCrossChainClient(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return bridge.NewFxTronBridge(viper.GetString("bridge-addr"), tronHttpClient, tronHttpClient, crossChainClient, crossChainClient, orcPrivKey, tronPrivateKey), nil
+}
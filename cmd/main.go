@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -8,12 +9,22 @@ import (
 
 	fxtronbridge "github.com/functionx/fx-tron-bridge"
 	"github.com/functionx/fx-tron-bridge/bridge"
+	"github.com/functionx/fx-tron-bridge/client"
+	"github.com/functionx/fx-tron-bridge/fxchain"
 	"github.com/functionx/fx-tron-bridge/internal/logger"
+	"github.com/functionx/fx-tron-bridge/internal/notifier"
 	"github.com/functionx/fx-tron-bridge/internal/utils"
 )
 
 const FxAddressPrefixEnv = "FX_ADDRESS_PREFIX"
-const LogLevelFlag = "log-level"
+const (
+	LogLevelFlag      = "log-level"
+	LogFormatFlag     = "log-format"
+	LogFileFlag       = "log-file"
+	LogMaxSizeFlag    = "log-max-size"
+	LogMaxAgeFlag     = "log-max-age"
+	LogMaxBackupsFlag = "log-max-backups"
+)
 
 func init() {
 	var prefix = os.Getenv(FxAddressPrefixEnv)
@@ -30,13 +41,15 @@ func main() {
 			if err := viper.BindPFlags(cmd.Flags()); err != nil {
 				return err
 			}
-			logger.Init(viper.GetString(LogLevelFlag))
-			return nil
+			_, err := logger.Init(viper.GetString(LogLevelFlag), viper.GetString(LogFormatFlag), logger.FileConfig{
+				Filename:   viper.GetString(LogFileFlag),
+				MaxSize:    viper.GetInt(LogMaxSizeFlag),
+				MaxAge:     viper.GetInt(LogMaxAgeFlag),
+				MaxBackups: viper.GetInt(LogMaxBackupsFlag),
+			})
+			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			bridgeAddr := viper.GetString("bridge-addr")
-			tronGrpc := viper.GetString("tron-grpc")
-			fxGrpc := viper.GetString("fx-grpc")
 			orcPrivKey, err := utils.DecryptFxPrivateKey(viper.GetString("fx-key"), viper.GetString("fx-pwd"))
 			if err != nil {
 				return err
@@ -45,15 +58,31 @@ func main() {
 			if err != nil {
 				return err
 			}
-			fxTronBridge, err := bridge.NewFxTronBridge(bridgeAddr, tronGrpc, fxGrpc, orcPrivKey, tronPrivateKey)
+			fxTronBridge, err := newFxTronBridge(orcPrivKey, tronPrivateKey)
 			if err != nil {
 				return err
 			}
+			if len(viper.GetString("tron-zmq")) > 0 {
+				zmqClient := client.NewTronZmqClient(viper.GetString("tron-zmq"), viper.GetString("bridge-addr"), fxTronBridge.EventSource)
+				go zmqClient.Run(context.Background())
+				fxTronBridge.EventSource = zmqClient
+			}
+			if len(viper.GetString("fx-rpc")) > 0 {
+				fxTronBridge.BlockSubscriber = fxchain.NewTendermintBlockSubscriber(viper.GetString("fx-rpc"))
+			}
+			notifier.Init(utils.SplitFlagValues(viper.GetString("alert-webhook")), viper.GetDuration("alert-repeat-interval"), viper.GetInt("alert-max-per-minute"))
 			if err = fxTronBridge.WaitNewBlock(); err != nil {
 				return err
 			}
-			fxtronbridge.StartBridgePrometheus()
-			return bridge.Run(fxTronBridge, viper.GetUint64("start-block-number"), viper.GetString("fees"))
+			if err = fxtronbridge.StartBridgePrometheus(viper.GetString("metrics-listen-addr"), viper.GetString("metrics-namespace")); err != nil {
+				return err
+			}
+			if len(viper.GetString("admin-listen-addr")) > 0 {
+				if err = fxtronbridge.StartAdminServer(viper.GetString("admin-listen-addr")); err != nil {
+					return err
+				}
+			}
+			return bridge.Run(fxTronBridge, viper.GetUint64("start-block-number"), viper.GetString("fees"), viper.GetFloat64("fee-balance-warn"), viper.GetFloat64("solvency-threshold"))
 		},
 	}
 
@@ -64,11 +93,28 @@ func main() {
 	utils.AddFlags(rootCmd, "tron-pwd", "", "tron pwd", false)
 	utils.AddFlags(rootCmd, "fees", "FX", "fees", false)
 	utils.AddFlags(rootCmd, "bridge-addr", "", "tron contract bridge-token address", true)
-	utils.AddFlags(rootCmd, "tron-grpc", "", "tron chain node", true)
+	utils.AddFlags(rootCmd, "tron-grpc", "", "tron chain node, tron-http-api is used instead when it is not set", false)
 	utils.AddFlags(rootCmd, "fx-grpc", "", "fx chain node grpc", true)
+	addGrpcFlags(rootCmd, "tron", "fx")
+	utils.AddFlags(rootCmd, "fx-rpc", "", "fx chain node tendermint rpc, e.g. http://127.0.0.1:26657, the singer confirms on every block pushed over its websocket instead of polling fx-grpc", false)
+	utils.AddFlags(rootCmd, "tron-http-api", "", "tron http api, e.g. https://api.trongrid.io, the bridge events are read from it, and the bridge state too when tron-grpc is not set", false)
+	utils.AddFlags(rootCmd, "tron-zmq", "", "java-tron event plugin zeromq endpoint, e.g. tcp://127.0.0.1:5555, with the block and transaction triggers enabled and ethCompatible set, the bridge events are pushed from it and the blocks it misses are polled", false)
+	utils.AddFlags(rootCmd, "fee-balance-warn", float64(100), "alert when the fx key fee balance is below this amount", false)
+	utils.AddFlags(rootCmd, "solvency-threshold", fxtronbridge.DefaultSolvencyThreshold, "alert when locked tokens and fx supply differ by more than this ratio", false)
+	utils.AddFlags(rootCmd, "alert-webhook", "", "comma separated webhook urls that receive alerts, slack incoming webhooks are supported", false)
+	utils.AddFlags(rootCmd, "alert-repeat-interval", notifier.DefaultRepeatInterval, "interval before a firing alert is sent again", false)
+	utils.AddFlags(rootCmd, "alert-max-per-minute", notifier.DefaultMaxPerMinute, "maximum number of alerts sent per minute", false)
+	utils.AddFlags(rootCmd, "metrics-listen-addr", fxtronbridge.DefaultPrometheusListenAddr, "prometheus metrics listen address", false)
+	utils.AddFlags(rootCmd, "metrics-namespace", fxtronbridge.DefaultPrometheusNamespace, "prometheus metrics namespace", false)
+	utils.AddFlags(rootCmd, "admin-listen-addr", fxtronbridge.DefaultAdminListenAddr, "admin api listen address, it has no authentication so keep it local, empty disables it", false)
 
-	rootCmd.AddCommand(fxtronbridge.NewVersionCmd())
+	rootCmd.AddCommand(fxtronbridge.NewVersionCmd(), newSlashingProtectionCmd(), newSolvencyCmd(), newAuditCmd(), newConfirmsCmd(), newContractCmd(), newTxCmd(), newDepositCmd())
 	rootCmd.PersistentFlags().String(LogLevelFlag, "info", "the logging level (debug|info|warn|error|dpanic|panic|fatal)")
+	rootCmd.PersistentFlags().String(LogFormatFlag, logger.FormatConsole, "the logging format (console|json)")
+	rootCmd.PersistentFlags().String(LogFileFlag, "", "also write logs to this file, rotated by size and age")
+	rootCmd.PersistentFlags().Int(LogMaxSizeFlag, 100, "the maximum size in megabytes of the log file before it gets rotated")
+	rootCmd.PersistentFlags().Int(LogMaxAgeFlag, 7, "the maximum number of days to retain rotated log files")
+	rootCmd.PersistentFlags().Int(LogMaxBackupsFlag, 10, "the maximum number of rotated log files to retain")
 	utils.SilenceCmdErrors(rootCmd)
 	utils.CheckErr(rootCmd.Execute())
 }
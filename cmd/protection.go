@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/internal/protection"
+)
+
+func newSlashingProtectionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slashing-protection",
+		Short: "Import or export the slashing-protection store of the tron key",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "export [file]",
+			Short: "Export the store in the slashing-protection interchange format, to stdout when no file is given",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				store, err := protection.Open(slashingProtectionPath(), "")
+				if err != nil {
+					return err
+				}
+				if len(args) <= 0 {
+					return store.Export(cmd.OutOrStdout())
+				}
+				file, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				return store.Export(file)
+			},
+		},
+		&cobra.Command{
+			Use:   "import <file>",
+			Short: "Merge a slashing-protection interchange file into the store, the bridge must be stopped",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				store, err := protection.OpenLocked(slashingProtectionPath(), "")
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				return store.Import(file)
+			},
+		},
+	)
+	return cmd
+}
+
+func slashingProtectionPath() string {
+	return path.Join(os.ExpandEnv(fxtronbridge.TronHome), protection.FileName)
+}
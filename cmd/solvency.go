@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+func newSolvencyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "solvency",
+		Short: "Reconcile the tokens locked by the tron bridge contract with the fx core supply",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fxBridge, err := newQueryFxTronBridge()
+			if err != nil {
+				return err
+			}
+			reports, err := fxBridge.Reconcile()
+			if err != nil {
+				return err
+			}
+			threshold := viper.GetFloat64("solvency-threshold")
+			mismatch := 0
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(writer, "TOKEN\tSYMBOL\tDENOM\tLOCKED\tSUPPLY\tPENDING\tDIFF\tRATIO\tSTATUS")
+			for _, report := range reports {
+				if report.Skipped {
+					_, _ = fmt.Fprintf(writer, "%s\t%s\t-\t-\t-\t-\t-\t-\toriginated\n", report.Token, report.Symbol)
+					continue
+				}
+				status := "ok"
+				if report.DiffRatio > threshold || report.DiffRatio < -threshold {
+					status = "mismatch"
+					mismatch++
+				}
+				_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.6f\t%s\n", report.Token, report.Symbol, report.Denom,
+					report.Locked, report.Supply, report.Pending, report.Diff, report.DiffRatio, status)
+			}
+			if err = writer.Flush(); err != nil {
+				return err
+			}
+			if mismatch > 0 {
+				return fmt.Errorf("%d bridge tokens differ by more than %v", mismatch, threshold)
+			}
+			return nil
+		},
+	}
+	utils.AddFlags(cmd, "bridge-addr", "", "tron contract bridge-token address", true)
+	utils.AddFlags(cmd, "tron-grpc", "", "tron chain node", true)
+	utils.AddFlags(cmd, "fx-grpc", "", "fx chain node grpc", true)
+	addGrpcFlags(cmd, "tron", "fx")
+	utils.AddFlags(cmd, "solvency-threshold", fxtronbridge.DefaultSolvencyThreshold, "report tokens whose locked amount and fx supply differ by more than this ratio", false)
+	return cmd
+}
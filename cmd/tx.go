@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/functionx/fx-tron-bridge/client"
+	"github.com/functionx/fx-tron-bridge/internal/utils"
+)
+
+const defaultTxExpiration = time.Hour
+
+func newTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx",
+		Short: "Build, sign offline and broadcast FxBridgeTron transactions",
+	}
+	buildCmd := &cobra.Command{
+		Use:   "build <method> [args...]",
+		Short: "Write an unsigned transaction calling a FxBridgeTron method to a file",
+		Long: "Write an unsigned transaction calling a FxBridgeTron method to a file.\n" +
+			"Addresses are base58, bytes32 are strings and arrays are comma separated.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, err := client.ParseBridgeArgs(args[0], args[1:])
+			if err != nil {
+				return err
+			}
+			data, err := client.PackBridgeCall(args[0], values...)
+			if err != nil {
+				return err
+			}
+			tronClient, err := newOfflineTxClient()
+			if err != nil {
+				return err
+			}
+			offlineTx, err := tronClient.BuildOfflineTx(viper.GetString("owner"), viper.GetString("bridge-addr"), data,
+				viper.GetInt64("fee-limit"), int32(viper.GetInt("permission-id")), viper.GetDuration("expiration"))
+			if err != nil {
+				return err
+			}
+			if err = offlineTx.Write(viper.GetString("file")); err != nil {
+				return err
+			}
+			printOfflineTx(cmd, offlineTx)
+			return nil
+		},
+	}
+	utils.AddFlags(buildCmd, "bridge-addr", "", "tron contract bridge-token address", true)
+	addOfflineTxClientFlags(buildCmd)
+	utils.AddFlags(buildCmd, "owner", "", "tron account that owns the transaction", true)
+	utils.AddFlags(buildCmd, "file", "", "unsigned transaction file", true)
+	utils.AddFlags(buildCmd, "fee-limit", int64(0), "fee limit in sun, estimated from the call energy when 0", false)
+	utils.AddFlags(buildCmd, "expiration", defaultTxExpiration, "time before the transaction expires, at most 24h", false)
+	utils.AddFlags(buildCmd, "permission-id", 0, "permission of the owner account the transaction is signed with, 0 is the owner permission", false)
+
+	signCmd := &cobra.Command{
+		Use:   "sign <file>",
+		Short: "Sign a transaction file with the tron keys, no network access is needed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			offlineTx, err := client.ReadOfflineTx(args[0])
+			if err != nil {
+				return err
+			}
+			printOfflineTx(cmd, offlineTx)
+			privKeys, err := decryptTronKeys()
+			if err != nil {
+				return err
+			}
+			if err = offlineTx.Sign(privKeys...); err != nil {
+				return err
+			}
+			if err = offlineTx.Write(args[0]); err != nil {
+				return err
+			}
+			return printSigners(cmd, offlineTx)
+		},
+	}
+	utils.AddFlags(signCmd, "tron-key", "", "tron keys, comma separated", true)
+	utils.AddFlags(signCmd, "tron-pwd", "", "tron pwd, or one pwd per tron key comma separated", false)
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge <file> <signed-file>...",
+		Short: "Add the signatures of partially signed copies of a transaction to the transaction file",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			offlineTx, err := client.ReadOfflineTx(args[0])
+			if err != nil {
+				return err
+			}
+			for _, path := range args[1:] {
+				signed, err := client.ReadOfflineTx(path)
+				if err != nil {
+					return err
+				}
+				if err = offlineTx.Merge(signed); err != nil {
+					return err
+				}
+			}
+			if err = offlineTx.Write(args[0]); err != nil {
+				return err
+			}
+			return printSigners(cmd, offlineTx)
+		},
+	}
+
+	broadcastCmd := &cobra.Command{
+		Use:   "broadcast <file>",
+		Short: "Broadcast a signed transaction file and wait for its receipt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			offlineTx, err := client.ReadOfflineTx(args[0])
+			if err != nil {
+				return err
+			}
+			printOfflineTx(cmd, offlineTx)
+			tronClient, err := newOfflineTxClient()
+			if err != nil {
+				return err
+			}
+			weight, err := tronClient.CheckPermission(offlineTx)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "permission: %d %s, weight: %d, threshold: %d\n", weight.Id, weight.Name, weight.Weight, weight.Threshold)
+			info, err := tronClient.BroadcastOfflineTx(offlineTx, viper.GetDuration("receipt-timeout"))
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "txid: %s, block: %d, energy: %d, fee: %d\n", hex.EncodeToString(info.GetId()),
+				info.GetBlockNumber(), info.GetReceipt().GetEnergyUsageTotal(), info.GetFee())
+			return nil
+		},
+	}
+	addOfflineTxClientFlags(broadcastCmd)
+	utils.AddFlags(broadcastCmd, "receipt-timeout", defaultReceiptTimeout, "time to wait for the transaction receipt", false)
+
+	cmd.AddCommand(buildCmd, signCmd, mergeCmd, broadcastCmd)
+	return cmd
+}
+
+// offlineTxClient builds and broadcasts the offline transactions, TronClient or TronHttpClient.
+type offlineTxClient interface {
+	BuildOfflineTx(owner, contractAddress string, data []byte, feeLimit int64, permissionId int32, expiration time.Duration) (*client.OfflineTx, error)
+	CheckPermission(offlineTx *client.OfflineTx) (*client.PermissionWeight, error)
+	BroadcastOfflineTx(offlineTx *client.OfflineTx, timeout time.Duration) (*core.TransactionInfo, error)
+}
+
+func addOfflineTxClientFlags(cmd *cobra.Command) {
+	utils.AddFlags(cmd, "tron-grpc", "", "tron chain node, tron-http-api is used instead when it is not set", false)
+	utils.AddFlags(cmd, "tron-http-api", "", "tron http api, e.g. https://api.trongrid.io", false)
+	addGrpcFlags(cmd, "tron")
+}
+
+func newOfflineTxClient() (offlineTxClient, error) {
+	if len(viper.GetString("tron-grpc")) > 0 {
+		return newTronClient()
+	}
+	if len(viper.GetString("tron-http-api")) > 0 {
+		return newTronHttpClient()
+	}
+	return nil, fmt.Errorf("tron-grpc or tron-http-api is required")
+}
+
+func printOfflineTx(cmd *cobra.Command, offlineTx *client.OfflineTx) {
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "contract:   %s\nowner:      %s\npermission: %d\ncall:       %s\nfee limit:  %d\nexpiration: %s\ntxid:       %s\nsignatures: %d\n",
+		offlineTx.Contract, offlineTx.Owner, offlineTx.PermissionId, offlineTx.Call, offlineTx.FeeLimit, offlineTx.Expiration.Format(time.RFC3339), offlineTx.TxId, len(offlineTx.Signatures))
+}
+
+func printSigners(cmd *cobra.Command, offlineTx *client.OfflineTx) error {
+	signers, err := offlineTx.Signers()
+	if err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "signed by:  %s\n", signer)
+	}
+	return nil
+}
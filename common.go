@@ -21,3 +21,23 @@ const (
 	thresholdVotePowerProportion = 66         // 66%
 	ThresholdVotePower           = totalPower * thresholdVotePowerProportion / 100
 )
+
+// AttestationPowerThreshold is the percent of the oracle power fx core needs to observe a claim.
+const AttestationPowerThreshold = 66
+
+const (
+	SignErrorAlertCount   = 3
+	ParamsRefreshInterval = 10 * time.Minute
+)
+
+const OracleSetDriftCheckInterval = time.Minute
+
+const (
+	SolvencyCheckInterval    = 10 * time.Minute
+	DefaultSolvencyThreshold = 0.001 // 0.1%
+)
+
+const (
+	BatchTimeoutCheckInterval = time.Minute
+	BatchTimeoutWarnBlocks    = 1200 // about one hour of tron blocks
+)
@@ -23,6 +23,7 @@ const TronSignaturePrefix = "\x19TRON Signed Message:\n32"
 type IEvent interface {
 	ToMsg(blockHeight uint64, orchestrator string) sdk.Msg
 	GetEventNonce() uint64
+	GetTxHash() ethcommon.Hash
 }
 
 func (event *FxBridgeTronTransactionBatchExecutedEvent) ToMsg(blockHeight uint64, bridgerAddress string) sdk.Msg {
@@ -40,6 +41,10 @@ func (event *FxBridgeTronTransactionBatchExecutedEvent) GetEventNonce() uint64 {
 	return event.EventNonce.Uint64()
 }
 
+func (event *FxBridgeTronTransactionBatchExecutedEvent) GetTxHash() ethcommon.Hash {
+	return event.Raw.TxHash
+}
+
 func (event *FxBridgeTronOracleSetUpdatedEvent) ToMsg(blockHeight uint64, bridgerAddress string) sdk.Msg {
 	members := make([]crosschaintypes.BridgeValidator, len(event.Oracles))
 	for i, oracleAddress := range event.Oracles {
@@ -62,6 +67,10 @@ func (event *FxBridgeTronOracleSetUpdatedEvent) GetEventNonce() uint64 {
 	return event.EventNonce.Uint64()
 }
 
+func (event *FxBridgeTronOracleSetUpdatedEvent) GetTxHash() ethcommon.Hash {
+	return event.Raw.TxHash
+}
+
 func (event *FxBridgeTronAddBridgeTokenEvent) ToMsg(blockHeight uint64, bridgerAddress string) sdk.Msg {
 	return &crosschaintypes.MsgBridgeTokenClaim{
 		EventNonce:     event.EventNonce.Uint64(),
@@ -80,6 +89,10 @@ func (event *FxBridgeTronAddBridgeTokenEvent) GetEventNonce() uint64 {
 	return event.EventNonce.Uint64()
 }
 
+func (event *FxBridgeTronAddBridgeTokenEvent) GetTxHash() ethcommon.Hash {
+	return event.Raw.TxHash
+}
+
 func (event *FxBridgeTronSendToFxEvent) ToMsg(blockHeight uint64, bridgerAddress string) sdk.Msg {
 	return &crosschaintypes.MsgSendToFxClaim{
 		EventNonce:     event.EventNonce.Uint64(),
@@ -98,6 +111,10 @@ func (event *FxBridgeTronSendToFxEvent) GetEventNonce() uint64 {
 	return event.EventNonce.Uint64()
 }
 
+func (event *FxBridgeTronSendToFxEvent) GetTxHash() ethcommon.Hash {
+	return event.Raw.TxHash
+}
+
 func UnpackLog(abi ethabi.ABI, out interface{}, event string, log ethtypes.Log) error {
 	if log.Topics[0] != abi.Events[event].ID {
 		return fmt.Errorf("event signature mismatch")
@@ -183,6 +200,18 @@ func AddressToString(addr ethcommon.Address) string {
 	return troncommon.EncodeCheck(addressByte)
 }
 
+// StringToAddress converts a base58 tron address to the 20 bytes address used by the contract abi.
+func StringToAddress(addr string) (ethcommon.Address, error) {
+	tronAddr, err := address.Base58ToAddress(addr)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	if len(tronAddr) != address.AddressLength || tronAddr[0] != address.TronBytePrefix {
+		return ethcommon.Address{}, fmt.Errorf("invalid tron address: %s", addr)
+	}
+	return ethcommon.BytesToAddress(tronAddr.Bytes()[1:]), nil
+}
+
 func hexByte32ToTargetIbc(bytes [32]byte) string {
 	for i := len(bytes) - 1; i >= 0; i-- {
 		if bytes[i] != 0 {
@@ -13,6 +13,19 @@ func TestAddressToString(t *testing.T) {
 	t.Log("tronAddress:", tronAddress)
 }
 
+func TestStringToAddress(t *testing.T) {
+	addr, err := StringToAddress("TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if AddressToString(addr) != "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR" {
+		t.Fatalf("unexpected address: %s", AddressToString(addr))
+	}
+	if _, err = StringToAddress("0x8a21bcef7269bd328bf843207bfe0d84dc3b68e9"); err == nil {
+		t.Fatal("expect invalid tron address")
+	}
+}
+
 func TestFixedBytes(t *testing.T) {
 	bytes := fixedBytes("fx/transfer")
 	t.Log("bytes:", bytes)
@@ -2,23 +2,37 @@ package fxchain
 
 import (
 	"context"
+	"net/url"
 
-	"github.com/functionx/fx-core/v3/client/grpc"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	fxgrpc "github.com/functionx/fx-core/v3/client/grpc"
 	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	fxtronbridge "github.com/functionx/fx-tron-bridge"
 )
 
 /* ======================================> Cross Chain gravity grpc <====================================== */
 
 type CrossChainClient struct {
-	*grpc.Client
+	*fxgrpc.Client
 	ctx context.Context
 }
 
 func NewCrossChainClient(ctx context.Context, grpcUrl string) (*CrossChainClient, error) {
-	client, err := grpc.NewClient(grpcUrl)
+	return NewCrossChainClientWithOptions(ctx, grpcUrl, fxtronbridge.GrpcOptions{})
+}
+
+// NewCrossChainClientWithOptions connects to a fx core node with the tls and header options.
+func NewCrossChainClientWithOptions(ctx context.Context, grpcUrl string, options fxtronbridge.GrpcOptions) (*CrossChainClient, error) {
+	conn, err := dialGrpc(grpcUrl, options)
 	if err != nil {
 		return nil, err
 	}
+	client := &fxgrpc.Client{ClientConn: conn}
 	client.WithContext(ctx)
 	cli := &CrossChainClient{
 		ctx:    ctx,
@@ -27,6 +41,18 @@ func NewCrossChainClient(ctx context.Context, grpcUrl string) (*CrossChainClient
 	return cli, nil
 }
 
+func dialGrpc(grpcUrl string, options fxtronbridge.GrpcOptions) (*grpc.ClientConn, error) {
+	parseUrl, err := url.Parse(grpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := options.DialOptions(parseUrl, "fx")
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Dial(parseUrl.Host, opts...)
+}
+
 func (cli *CrossChainClient) CurrentOracleSet(chainName string) (*crosschaintypes.OracleSet, error) {
 	response, err := cli.CrosschainQuery().CurrentOracleSet(cli.ctx, &crosschaintypes.QueryCurrentOracleSetRequest{ChainName: chainName})
 	if err != nil {
@@ -43,6 +69,14 @@ func (cli *CrossChainClient) TokenToDenom(token, chainName string) (*crosschaint
 	return response, nil
 }
 
+func (cli *CrossChainClient) SupplyOf(denom string) (sdk.Coin, error) {
+	response, err := cli.BankQuery().SupplyOf(cli.ctx, &banktypes.QuerySupplyOfRequest{Denom: denom})
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+	return response.Amount, nil
+}
+
 func (cli *CrossChainClient) BatchFees(chainName string) ([]*crosschaintypes.BatchFees, error) {
 	response, err := cli.CrosschainQuery().BatchFees(cli.ctx, &crosschaintypes.QueryBatchFeeRequest{ChainName: chainName})
 	if err != nil {
@@ -59,6 +93,18 @@ func (cli *CrossChainClient) BatchConfirms(nonce uint64, tokenContract, chainNam
 	return response.Confirms, nil
 }
 
+// BatchRequestByNonce returns nil when fx core no longer stores the batch, it is removed once executed on tron.
+func (cli *CrossChainClient) BatchRequestByNonce(nonce uint64, tokenContract, chainName string) (*crosschaintypes.OutgoingTxBatch, error) {
+	response, err := cli.CrosschainQuery().BatchRequestByNonce(cli.ctx, &crosschaintypes.QueryBatchRequestByNonceRequest{Nonce: nonce, TokenContract: tokenContract, ChainName: chainName})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response.Batch, nil
+}
+
 func (cli *CrossChainClient) OutgoingTxBatches(chainName string) ([]*crosschaintypes.OutgoingTxBatch, error) {
 	response, err := cli.CrosschainQuery().OutgoingTxBatches(cli.ctx, &crosschaintypes.QueryOutgoingTxBatchesRequest{ChainName: chainName})
 	if err != nil {
@@ -123,6 +169,14 @@ func (cli *CrossChainClient) GetOracleByBridgerAddr(bridgerAddress string, chain
 	return response.Oracle, nil
 }
 
+func (cli *CrossChainClient) Oracles(chainName string) ([]crosschaintypes.Oracle, error) {
+	response, err := cli.CrosschainQuery().Oracles(cli.ctx, &crosschaintypes.QueryOraclesRequest{ChainName: chainName})
+	if err != nil {
+		return nil, err
+	}
+	return response.Oracles, nil
+}
+
 func (cli *CrossChainClient) LastEventNonceByAddr(bridgerAddress string, chainName string) (uint64, error) {
 	response, err := cli.CrosschainQuery().LastEventNonceByAddr(cli.ctx, &crosschaintypes.QueryLastEventNonceByAddrRequest{BridgerAddress: bridgerAddress, ChainName: chainName})
 	if err != nil {
@@ -0,0 +1,58 @@
+package fxchain
+
+import (
+	"context"
+
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const newBlockHeaderQuery = "tm.event='NewBlockHeader'"
+
+// TendermintBlockSubscriber pushes the new fx core blocks from the websocket of the tendermint rpc.
+type TendermintBlockSubscriber struct {
+	rpcUrl string
+}
+
+func NewTendermintBlockSubscriber(rpcUrl string) *TendermintBlockSubscriber {
+	return &TendermintBlockSubscriber{rpcUrl: rpcUrl}
+}
+
+// SubscribeNewBlock sends the height of every committed block until ctx is done, the channel is closed
+// then. The websocket reconnects by itself, a subscription that stays silent is left to the caller.
+func (s *TendermintBlockSubscriber) SubscribeNewBlock(ctx context.Context) (<-chan int64, error) {
+	rpcClient, err := rpchttp.New(s.rpcUrl, "/websocket")
+	if err != nil {
+		return nil, err
+	}
+	if err = rpcClient.Start(); err != nil {
+		return nil, err
+	}
+	events, err := rpcClient.Subscribe(ctx, "fx-tron-bridge", newBlockHeaderQuery)
+	if err != nil {
+		_ = rpcClient.Stop()
+		return nil, err
+	}
+	heights := make(chan int64, 1)
+	go func() {
+		defer close(heights)
+		defer func() { _ = rpcClient.Stop() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				header, ok := event.Data.(tmtypes.EventDataNewBlockHeader)
+				if !ok {
+					continue
+				}
+				select {
+				case heights <- header.Header.Height:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return heights, nil
+}
@@ -0,0 +1,79 @@
+package fxchain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// newTestTendermintRpc serves the subscribe route of the tendermint rpc websocket, every subscription is
+// sent a vote event and a new block header each 10ms until the connection closes.
+func newTestTendermintRpc(t *testing.T) string {
+	subscribe := func(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
+		subscriptionId := ctx.JSONReq.ID
+		go func() {
+			for height := int64(1); ; height++ {
+				time.Sleep(10 * time.Millisecond)
+				for _, data := range []tmtypes.TMEventData{tmtypes.EventDataVote{}, tmtypes.EventDataNewBlockHeader{Header: tmtypes.Header{Height: height}}} {
+					resp := rpctypes.NewRPCSuccessResponse(subscriptionId, &ctypes.ResultEvent{Query: query, Data: data})
+					if err := ctx.WSConn.WriteRPCResponse(context.Background(), resp); err != nil {
+						return
+					}
+				}
+			}
+		}()
+		return &ctypes.ResultSubscribe{}, nil
+	}
+	funcs := map[string]*rpcserver.RPCFunc{"subscribe": rpcserver.NewWSRPCFunc(subscribe, "query")}
+	mux := http.NewServeMux()
+	rpcserver.RegisterRPCFuncs(mux, funcs, log.NewNopLogger())
+	wm := rpcserver.NewWebsocketManager(funcs)
+	wm.SetLogger(log.NewNopLogger())
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestTendermintBlockSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heights, err := NewTendermintBlockSubscriber(newTestTendermintRpc(t)).SubscribeNewBlock(ctx)
+	require.NoError(t, err)
+
+	receive := func() (int64, bool) {
+		select {
+		case height, ok := <-heights:
+			return height, ok
+		case <-time.After(5 * time.Second):
+			t.Fatal("no new block height")
+			return 0, false
+		}
+	}
+	first, ok := receive()
+	require.True(t, ok)
+	require.Positive(t, first)
+	next, ok := receive()
+	require.True(t, ok)
+	require.Greater(t, next, first)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok = receive()
+		return !ok
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestTendermintBlockSubscriberUnreachable(t *testing.T) {
+	_, err := NewTendermintBlockSubscriber("http://127.0.0.1:1").SubscribeNewBlock(context.Background())
+	require.Error(t, err)
+}
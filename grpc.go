@@ -0,0 +1,120 @@
+package fxtronbridge
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// TronApiKeyHeader is the header TronGrid reads the api key from.
+const TronApiKeyHeader = "TRON-PRO-API-KEY"
+
+// GrpcOptions configures the transport of the tron and fx core grpc clients. The tls options only apply
+// to https urls, which verify the server against the system roots and CAFile.
+type GrpcOptions struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	// Headers are sent as metadata with every call, e.g. TRON-PRO-API-KEY
+	Headers map[string]string
+}
+
+// DialOptions returns the credentials for the url scheme and the latency and header interceptors,
+// clientName labels the rpc latency metrics.
+func (o GrpcOptions) DialOptions(parseUrl *url.URL, clientName string) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	if parseUrl.Scheme == "https" {
+		tlsConfig, err := o.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		if len(o.CAFile) > 0 || len(o.CertFile) > 0 || o.InsecureSkipVerify {
+			return nil, fmt.Errorf("%s grpc tls options need an https url: %s", clientName, parseUrl.String())
+		}
+		opts = append(opts, grpc.WithInsecure())
+	}
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(RpcLatencyInterceptor(clientName), HeaderUnaryInterceptor(o.Headers)),
+		grpc.WithStreamInterceptor(HeaderStreamInterceptor(o.Headers)),
+	)
+	return opts, nil
+}
+
+// TLSConfig trusts the system roots and CAFile, and presents CertFile and KeyFile as the client certificate.
+func (o GrpcOptions) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: o.InsecureSkipVerify}
+	if len(o.CAFile) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		caPem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		if !rootCAs.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("no certificate found in ca file %s", o.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if len(o.CertFile) > 0 || len(o.KeyFile) > 0 {
+		if len(o.CertFile) <= 0 || len(o.KeyFile) <= 0 {
+			return nil, fmt.Errorf("client certificate needs both the cert file and the key file")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ParseGrpcHeaders parses key=value headers.
+func ParseGrpcHeaders(values []string) (map[string]string, error) {
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		key, headerValue, ok := strings.Cut(value, "=")
+		if !ok || len(strings.TrimSpace(key)) <= 0 {
+			return nil, fmt.Errorf("invalid grpc header %q, expect key=value", value)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// HeaderUnaryInterceptor adds headers to the outgoing metadata of every unary call.
+func HeaderUnaryInterceptor(headers map[string]string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(appendHeaders(ctx, headers), method, req, reply, cc, opts...)
+	}
+}
+
+// HeaderStreamInterceptor adds headers to the outgoing metadata of every stream.
+func HeaderStreamInterceptor(headers map[string]string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(appendHeaders(ctx, headers), desc, cc, method, opts...)
+	}
+}
+
+func appendHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) <= 0 {
+		return ctx
+	}
+	pairs := make([]string, 0, len(headers)*2)
+	for key, value := range headers {
+		pairs = append(pairs, key, value)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
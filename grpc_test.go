@@ -0,0 +1,202 @@
+package fxtronbridge
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPem []byte
+	keyPem  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parentCert, parentKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPem:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// startTLSServer runs a health server that requires a client certificate signed by ca and sends the
+// incoming metadata to the returned channel.
+func startTLSServer(t *testing.T, ca, server *testCert) (string, chan metadata.MD) {
+	serverCert, err := tls.X509KeyPair(server.certPem, server.keyPem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	received := make(chan metadata.MD, 1)
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    clientCAs,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			MinVersion:   tls.VersionTLS12,
+		})),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			received <- md
+			return handler(ctx, req)
+		}),
+	)
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+	return "https://" + listener.Addr().String(), received
+}
+
+func checkHealth(t *testing.T, rawUrl string, options GrpcOptions) error {
+	parseUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := options.DialOptions(parseUrl, "test")
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(parseUrl.Host, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestGrpcOptionsMutualTLS(t *testing.T) {
+	ca := newTestCert(t, "test ca", nil, x509.ExtKeyUsageAny)
+	server := newTestCert(t, "127.0.0.1", ca, x509.ExtKeyUsageServerAuth)
+	client := newTestCert(t, "bridge", ca, x509.ExtKeyUsageClientAuth)
+	rawUrl, received := startTLSServer(t, ca, server)
+
+	options := GrpcOptions{
+		CAFile:   writeTestFile(t, "ca.pem", ca.certPem),
+		CertFile: writeTestFile(t, "client.pem", client.certPem),
+		KeyFile:  writeTestFile(t, "client.key", client.keyPem),
+		Headers:  map[string]string{TronApiKeyHeader: "api-key"},
+	}
+	if err := checkHealth(t, rawUrl, options); err != nil {
+		t.Fatal(err)
+	}
+	md := <-received
+	if values := md.Get(TronApiKeyHeader); len(values) != 1 || values[0] != "api-key" {
+		t.Fatalf("expect header %s api-key, got %v", TronApiKeyHeader, values)
+	}
+
+	skipVerify := GrpcOptions{CertFile: options.CertFile, KeyFile: options.KeyFile, InsecureSkipVerify: true}
+	if err := checkHealth(t, rawUrl, skipVerify); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server certificate is not signed by a system root
+	if err := checkHealth(t, rawUrl, GrpcOptions{CertFile: options.CertFile, KeyFile: options.KeyFile}); err == nil {
+		t.Fatal("expect unknown authority error")
+	}
+	// the server requires a client certificate
+	if err := checkHealth(t, rawUrl, GrpcOptions{CAFile: options.CAFile}); err == nil {
+		t.Fatal("expect missing client certificate error")
+	}
+}
+
+func TestGrpcOptionsInvalid(t *testing.T) {
+	httpUrl, err := url.Parse("http://127.0.0.1:50051")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (GrpcOptions{InsecureSkipVerify: true}).DialOptions(httpUrl, "test"); err == nil {
+		t.Fatal("expect tls options to need an https url")
+	}
+	if _, err = (GrpcOptions{Headers: map[string]string{"key": "value"}}).DialOptions(httpUrl, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (GrpcOptions{CertFile: "client.pem"}).TLSConfig(); err == nil {
+		t.Fatal("expect the cert file to need the key file")
+	}
+	if _, err = (GrpcOptions{CAFile: writeTestFile(t, "ca.pem", []byte("not a certificate"))}).TLSConfig(); err == nil {
+		t.Fatal("expect no certificate in the ca file")
+	}
+}
+
+func TestParseGrpcHeaders(t *testing.T) {
+	headers, err := ParseGrpcHeaders([]string{"TRON-PRO-API-KEY = abc", "x-token=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 || headers["TRON-PRO-API-KEY"] != "abc" || headers["x-token"] != "a=b" {
+		t.Fatalf("unexpected headers %v", headers)
+	}
+	for _, value := range []string{"abc", "=abc"} {
+		if _, err = ParseGrpcHeaders([]string{value}); err == nil {
+			t.Fatalf("expect %q to be invalid", value)
+		}
+	}
+}
@@ -2,23 +2,41 @@ package logger
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+const (
+	FormatConsole = "console"
+	FormatJson    = "json"
+)
+
+// FileConfig enables writing logs to a file, the file is rotated once it reaches MaxSize megabytes
+// and rotated files older than MaxAge days are removed.
+type FileConfig struct {
+	Filename   string
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+}
+
 var logger *zap.SugaredLogger
+var atomicLevel = zap.NewAtomicLevel()
 
 func init() {
-	Init("info")
+	if _, err := Init("info", FormatConsole, FileConfig{}); err != nil {
+		panic(err.Error())
+	}
 }
 
-func Init(level string) *zap.SugaredLogger {
-	l := new(zapcore.Level)
-	if err := l.Set(level); err != nil {
-		panic(err.Error())
+func Init(level, format string, file FileConfig) (*zap.SugaredLogger, error) {
+	if err := atomicLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, err
 	}
 	var logCfg = zapcore.EncoderConfig{
 		NameKey:        "logger",
@@ -34,17 +52,42 @@ func Init(level string) *zap.SugaredLogger {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 		EncodeName:     zapcore.FullNameEncoder,
 	}
-	logger = NewLogger(*l, logCfg).Sugar()
+	var encoder zapcore.Encoder
+	switch format {
+	case FormatConsole:
+		encoder = zapcore.NewConsoleEncoder(logCfg)
+	case FormatJson:
+		logCfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		logCfg.EncodeDuration = zapcore.MillisDurationEncoder
+		encoder = zapcore.NewJSONEncoder(logCfg)
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", format)
+	}
+	writer := zapcore.AddSync(os.Stdout)
+	if len(file.Filename) > 0 {
+		writer = zapcore.NewMultiWriteSyncer(writer, zapcore.AddSync(&lumberjack.Logger{
+			Filename:   file.Filename,
+			MaxSize:    file.MaxSize,
+			MaxAge:     file.MaxAge,
+			MaxBackups: file.MaxBackups,
+			LocalTime:  true,
+		}))
+	}
+	logger = NewLogger(encoder, writer).Sugar()
 	_ = logger.Sync()
-	return logger
+	return logger, nil
 }
 
-func NewLogger(level zapcore.Level, encoderConfig zapcore.EncoderConfig) *zap.Logger {
-	encoder := zapcore.NewConsoleEncoder(encoderConfig)
-	core := zapcore.NewCore(encoder, os.Stdout, zap.NewAtomicLevelAt(level))
+func NewLogger(encoder zapcore.Encoder, writer zapcore.WriteSyncer) *zap.Logger {
+	core := zapcore.NewCore(encoder, writer, atomicLevel)
 	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Development(), zap.AddStacktrace(zapcore.DPanicLevel))
 }
 
+// LevelHandler reports the current log level on GET and changes it on PUT, e.g. {"level":"debug"}.
+func LevelHandler() http.Handler {
+	return atomicLevel
+}
+
 func formatEncodeTime(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(fmt.Sprintf("%d-%02d-%02d %02d:%02d:%02d", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()))
 }
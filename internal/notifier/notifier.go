@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/functionx/fx-tron-bridge/internal/logger"
+)
+
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+const (
+	AlertBridgeBehind         = "bridge_behind"
+	AlertOracleInactive       = "oracle_inactive"
+	AlertKeyMismatch          = "key_mismatch"
+	AlertLowFeeBalance        = "low_fee_balance"
+	AlertSignError            = "sign_error"
+	AlertSlashingProtection   = "slashing_protection"
+	AlertCheckpointMismatch   = "checkpoint_mismatch"
+	AlertOracleSetUnreachable = "oracle_set_unreachable"
+	AlertSolvencyMismatch     = "solvency_mismatch"
+	AlertBatchTimeout         = "batch_timeout"
+)
+
+const (
+	DefaultRepeatInterval = 30 * time.Minute
+	DefaultMaxPerMinute   = 10
+)
+
+// Alert is the json body posted to every webhook, the text field makes it a valid slack incoming webhook payload.
+type Alert struct {
+	Text    string    `json:"text"`
+	Name    string    `json:"name"`
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type alertState struct {
+	message  string
+	lastSent time.Time
+}
+
+// Notifier posts alerts to webhooks. A firing alert is sent again only after the repeat interval
+// has passed, and no more than maxPerMinute alerts are posted in any minute.
+type Notifier struct {
+	webhooks       []string
+	repeatInterval time.Duration
+	maxPerMinute   int
+	client         *http.Client
+
+	mu          sync.Mutex
+	active      map[string]*alertState
+	windowStart time.Time
+	windowCount int
+}
+
+func NewNotifier(webhooks []string, repeatInterval time.Duration, maxPerMinute int) *Notifier {
+	return &Notifier{
+		webhooks:       webhooks,
+		repeatInterval: repeatInterval,
+		maxPerMinute:   maxPerMinute,
+		client:         &http.Client{Timeout: 10 * time.Second},
+		active:         make(map[string]*alertState),
+	}
+}
+
+// Fire raises the alert name, or refreshes it when it is already firing.
+func (n *Notifier) Fire(name, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	state, ok := n.active[name]
+	if ok && now.Sub(state.lastSent) < n.repeatInterval {
+		state.message = message
+		return
+	}
+	if !n.allow(now) {
+		logger.Warnw("alert rate limited", "alert", name, "message", message)
+		return
+	}
+	n.active[name] = &alertState{message: message, lastSent: now}
+	n.send(Alert{Name: name, Status: StatusFiring, Message: message, Time: now})
+}
+
+// Resolve clears the alert name, a resolved notification is only sent if the alert was firing.
+func (n *Notifier) Resolve(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	state, ok := n.active[name]
+	if !ok {
+		return
+	}
+	delete(n.active, name)
+	n.send(Alert{Name: name, Status: StatusResolved, Message: state.message, Time: time.Now()})
+}
+
+func (n *Notifier) IsFiring(name string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, ok := n.active[name]
+	return ok
+}
+
+func (n *Notifier) allow(now time.Time) bool {
+	if n.maxPerMinute <= 0 {
+		return true
+	}
+	if now.Sub(n.windowStart) >= time.Minute {
+		n.windowStart = now
+		n.windowCount = 0
+	}
+	if n.windowCount >= n.maxPerMinute {
+		return false
+	}
+	n.windowCount++
+	return true
+}
+
+func (n *Notifier) send(alert Alert) {
+	alert.Text = fmt.Sprintf("[%s] fx tron bridge %s: %s", alert.Status, alert.Name, alert.Message)
+	if alert.Status == StatusFiring {
+		logger.Warnw("alert firing", "alert", alert.Name, "message", alert.Message)
+	} else {
+		logger.Infow("alert resolved", "alert", alert.Name)
+	}
+	if len(n.webhooks) <= 0 {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		logger.Errorw("marshal alert fail", "alert", alert.Name, "error", err)
+		return
+	}
+	for _, webhook := range n.webhooks {
+		go n.post(webhook, alert.Name, body)
+	}
+}
+
+func (n *Notifier) post(webhook, name string, body []byte) {
+	resp, err := n.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Errorw("post alert fail", "alert", name, "webhook", webhook, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.Errorw("post alert fail", "alert", name, "webhook", webhook, "status", resp.StatusCode)
+	}
+}
+
+var notifier = NewNotifier(nil, DefaultRepeatInterval, DefaultMaxPerMinute)
+
+func Init(webhooks []string, repeatInterval time.Duration, maxPerMinute int) {
+	notifier = NewNotifier(webhooks, repeatInterval, maxPerMinute)
+}
+
+func Fire(name, format string, args ...interface{}) {
+	notifier.Fire(name, format, args...)
+}
+
+func Resolve(name string) {
+	notifier.Resolve(name)
+}
+
+func IsFiring(name string) bool {
+	return notifier.IsFiring(name)
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhook(t *testing.T) (*httptest.Server, chan Alert) {
+	alerts := make(chan Alert, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	t.Cleanup(server.Close)
+	return server, alerts
+}
+
+func receive(t *testing.T, alerts chan Alert) Alert {
+	select {
+	case alert := <-alerts:
+		return alert
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert not received")
+	}
+	return Alert{}
+}
+
+func expectNothing(t *testing.T, alerts chan Alert) {
+	select {
+	case alert := <-alerts:
+		t.Fatalf("unexpected alert: %v", alert)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestNotifierFireAndResolve(t *testing.T) {
+	server, alerts := newTestWebhook(t)
+	n := NewNotifier([]string{server.URL}, time.Hour, 0)
+
+	n.Fire(AlertOracleInactive, "bridger %s", "fx1abc")
+	alert := receive(t, alerts)
+	require.Equal(t, AlertOracleInactive, alert.Name)
+	require.Equal(t, StatusFiring, alert.Status)
+	require.Equal(t, "bridger fx1abc", alert.Message)
+	require.Contains(t, alert.Text, "oracle_inactive")
+
+	// the same alert is deduplicated until the repeat interval passes
+	n.Fire(AlertOracleInactive, "bridger %s", "fx1abc")
+	expectNothing(t, alerts)
+	require.True(t, n.IsFiring(AlertOracleInactive))
+
+	n.Resolve(AlertOracleInactive)
+	alert = receive(t, alerts)
+	require.Equal(t, StatusResolved, alert.Status)
+	require.False(t, n.IsFiring(AlertOracleInactive))
+
+	// resolving an alert that is not firing sends nothing
+	n.Resolve(AlertOracleInactive)
+	expectNothing(t, alerts)
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	server, alerts := newTestWebhook(t)
+	n := NewNotifier([]string{server.URL}, time.Hour, 2)
+
+	n.Fire(AlertBridgeBehind, "behind %d", 1)
+	n.Fire(AlertLowFeeBalance, "balance %d", 2)
+	n.Fire(AlertSignError, "errors %d", 3)
+	receive(t, alerts)
+	receive(t, alerts)
+	expectNothing(t, alerts)
+	require.False(t, n.IsFiring(AlertSignError))
+}
@@ -0,0 +1,266 @@
+// Package protection keeps a local record of every digest the bridger signed with its tron key,
+// so that a compromised or forked fx core node can not make it sign two different digests for
+// the same oracle set or batch nonce.
+//
+// The store is saved, imported and exported in the following JSON interchange format:
+//
+//	{
+//	  "version": 1,
+//	  "tron_address": "TFysCB929XGezbnyumoFScyevjDggu3BPq",
+//	  "signatures": [
+//	    {"kind": "oracle_set", "token": "", "nonce": 2, "digest": "0x3f1c...e2"},
+//	    {"kind": "batch", "token": "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", "nonce": 3, "digest": "0x8ab0...41"}
+//	  ]
+//	}
+//
+// kind is "oracle_set" or "batch", token is the batch token contract and empty for oracle sets,
+// digest is the 0x prefixed hex of the 32 bytes hash that was signed.
+package protection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	KindOracleSet = "oracle_set"
+	KindBatch     = "batch"
+)
+
+const (
+	FormatVersion = 1
+	FileName      = "slashing_protection.json"
+)
+
+var (
+	ErrConflictingDigest = errors.New("conflicting digest for a signed nonce")
+	ErrLocked            = errors.New("slashing protection is locked by another process, stop the bridge first")
+)
+
+type Signature struct {
+	Kind   string        `json:"kind"`
+	Token  string        `json:"token"`
+	Nonce  uint64        `json:"nonce"`
+	Digest hexutil.Bytes `json:"digest"`
+}
+
+type Interchange struct {
+	Version     int         `json:"version"`
+	TronAddress string      `json:"tron_address"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type signatureKey struct {
+	kind  string
+	token string
+	nonce uint64
+}
+
+// Store is the slashing-protection database, it is saved to its file before a signature is allowed.
+type Store struct {
+	mu          sync.Mutex
+	path        string
+	tronAddress string
+	signatures  map[signatureKey]Signature
+	lockFile    *os.File
+}
+
+// Open loads the store saved at path, a missing file is an empty store. When tronAddress is not empty
+// the store must belong to it, an empty tronAddress accepts the address saved in the file.
+func Open(path, tronAddress string) (*Store, error) {
+	store := &Store{
+		path:        path,
+		tronAddress: tronAddress,
+		signatures:  make(map[signatureKey]Signature),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, err
+	}
+	if err = store.merge(data); err != nil {
+		return nil, fmt.Errorf("load slashing protection %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// OpenLocked opens the store like Open while holding an exclusive lock on path.lock until Close. The running
+// bridge and the import both take the lock, so an import can not be overwritten by the saves of the bridge.
+func OpenLocked(path, tronAddress string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	lockFile, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lockFile.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		return nil, err
+	}
+	store, err := Open(path, tronAddress)
+	if err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+	store.lockFile = lockFile
+	return store, nil
+}
+
+// Close releases the lock taken by OpenLocked.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockFile == nil {
+		return nil
+	}
+	err := s.lockFile.Close()
+	s.lockFile = nil
+	return err
+}
+
+func (s *Store) TronAddress() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tronAddress
+}
+
+// CheckAndRecord records that digest is signed for (kind, token, nonce). Signing the same digest again
+// is allowed, a different digest for a recorded nonce returns ErrConflictingDigest.
+func (s *Store) CheckAndRecord(kind, token string, nonce uint64, digest []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := signatureKey{kind: kind, token: token, nonce: nonce}
+	if signed, ok := s.signatures[key]; ok {
+		if !bytes.Equal(signed.Digest, digest) {
+			return fmt.Errorf("%w, kind: %s, token: %s, nonce: %d, signed: %s, requested: %s",
+				ErrConflictingDigest, kind, token, nonce, signed.Digest.String(), hexutil.Encode(digest))
+		}
+		return nil
+	}
+	s.signatures[key] = Signature{Kind: kind, Token: token, Nonce: nonce, Digest: append([]byte{}, digest...)}
+	if err := s.save(); err != nil {
+		delete(s.signatures, key)
+		return err
+	}
+	return nil
+}
+
+// Export writes the store in the interchange format.
+func (s *Store) Export(w io.Writer) error {
+	s.mu.Lock()
+	data, err := s.marshal()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Import merges an interchange file into the store, nothing is imported if one of its
+// signatures conflicts with the store or it belongs to another tron address.
+func (s *Store) Import(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tronAddress := s.tronAddress
+	signatures := make(map[signatureKey]Signature, len(s.signatures))
+	for key, signature := range s.signatures {
+		signatures[key] = signature
+	}
+	if err = s.merge(data); err != nil {
+		s.tronAddress, s.signatures = tronAddress, signatures
+		return err
+	}
+	if err = s.save(); err != nil {
+		s.tronAddress, s.signatures = tronAddress, signatures
+		return err
+	}
+	return nil
+}
+
+func (s *Store) merge(data []byte) error {
+	var interchange Interchange
+	if err := json.Unmarshal(data, &interchange); err != nil {
+		return err
+	}
+	if interchange.Version != FormatVersion {
+		return fmt.Errorf("unsupported slashing protection version: %d", interchange.Version)
+	}
+	if len(s.tronAddress) > 0 && len(interchange.TronAddress) > 0 && s.tronAddress != interchange.TronAddress {
+		return fmt.Errorf("slashing protection belongs to %s, expect %s", interchange.TronAddress, s.tronAddress)
+	}
+	if len(s.tronAddress) <= 0 {
+		s.tronAddress = interchange.TronAddress
+	}
+	for _, signature := range interchange.Signatures {
+		if signature.Kind != KindOracleSet && signature.Kind != KindBatch {
+			return fmt.Errorf("unknown signature kind: %s", signature.Kind)
+		}
+		key := signatureKey{kind: signature.Kind, token: signature.Token, nonce: signature.Nonce}
+		if signed, ok := s.signatures[key]; ok && !bytes.Equal(signed.Digest, signature.Digest) {
+			return fmt.Errorf("%w, kind: %s, token: %s, nonce: %d", ErrConflictingDigest, signature.Kind, signature.Token, signature.Nonce)
+		}
+		s.signatures[key] = signature
+	}
+	return nil
+}
+
+func (s *Store) marshal() ([]byte, error) {
+	interchange := Interchange{
+		Version:     FormatVersion,
+		TronAddress: s.tronAddress,
+		Signatures:  make([]Signature, 0, len(s.signatures)),
+	}
+	for _, signature := range s.signatures {
+		interchange.Signatures = append(interchange.Signatures, signature)
+	}
+	sort.Slice(interchange.Signatures, func(i, j int) bool {
+		a, b := interchange.Signatures[i], interchange.Signatures[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Token != b.Token {
+			return a.Token < b.Token
+		}
+		return a.Nonce < b.Nonce
+	})
+	return json.MarshalIndent(interchange, "", "  ")
+}
+
+// save writes the store to a temporary file first, so a crash never leaves a truncated store.
+func (s *Store) save() error {
+	data, err := s.marshal()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmpFile := s.path + ".tmp"
+	if err = os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.path)
+}
@@ -0,0 +1,76 @@
+package protection
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testTronAddress = "TFysCB929XGezbnyumoFScyevjDggu3BPq"
+
+func TestCheckAndRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, err := Open(path, testTronAddress)
+	require.NoError(t, err)
+
+	require.NoError(t, store.CheckAndRecord(KindBatch, "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", 3, []byte{0x01}))
+	// signing the same digest again is safe
+	require.NoError(t, store.CheckAndRecord(KindBatch, "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", 3, []byte{0x01}))
+	err = store.CheckAndRecord(KindBatch, "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", 3, []byte{0x02})
+	require.True(t, errors.Is(err, ErrConflictingDigest))
+	// the same nonce of another token or kind is independent
+	require.NoError(t, store.CheckAndRecord(KindBatch, "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f", 3, []byte{0x02}))
+	require.NoError(t, store.CheckAndRecord(KindOracleSet, "", 3, []byte{0x02}))
+
+	reopened, err := Open(path, testTronAddress)
+	require.NoError(t, err)
+	err = reopened.CheckAndRecord(KindBatch, "TFETBkg3wrgEEDPXEPZCem4tegsaTw2fwR", 3, []byte{0x02})
+	require.True(t, errors.Is(err, ErrConflictingDigest))
+
+	_, err = Open(path, "TWF75HQEiMJpKZbX1CE6iwXfwU7ZZm7T7f")
+	require.Error(t, err)
+}
+
+func TestExportImport(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), FileName), testTronAddress)
+	require.NoError(t, err)
+	require.NoError(t, store.CheckAndRecord(KindOracleSet, "", 2, []byte{0xaa}))
+
+	var buf bytes.Buffer
+	require.NoError(t, store.Export(&buf))
+	require.Contains(t, buf.String(), `"digest": "0xaa"`)
+
+	imported, err := Open(filepath.Join(t.TempDir(), FileName), "")
+	require.NoError(t, err)
+	require.NoError(t, imported.Import(bytes.NewReader(buf.Bytes())))
+	require.Equal(t, testTronAddress, imported.TronAddress())
+	require.Error(t, imported.CheckAndRecord(KindOracleSet, "", 2, []byte{0xbb}))
+
+	conflict := `{"version":1,"tron_address":"` + testTronAddress + `","signatures":[
+		{"kind":"oracle_set","token":"","nonce":4,"digest":"0x01"},
+		{"kind":"oracle_set","token":"","nonce":2,"digest":"0xbb"}]}`
+	require.Error(t, imported.Import(strings.NewReader(conflict)))
+	// a rejected import leaves the store untouched
+	require.NoError(t, imported.CheckAndRecord(KindOracleSet, "", 4, []byte{0x02}))
+}
+
+func TestOpenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, err := OpenLocked(path, testTronAddress)
+	require.NoError(t, err)
+	require.NoError(t, store.CheckAndRecord(KindOracleSet, "", 2, []byte{0xaa}))
+
+	// an import while the bridge holds the store is refused
+	_, err = OpenLocked(path, "")
+	require.True(t, errors.Is(err, ErrLocked))
+
+	require.NoError(t, store.Close())
+	imported, err := OpenLocked(path, "")
+	require.NoError(t, err)
+	defer imported.Close()
+	require.Equal(t, testTronAddress, imported.TronAddress())
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -22,6 +24,17 @@ func SilenceCmdErrors(cmd *cobra.Command) {
 	}
 }
 
+// SplitFlagValues splits a comma separated flag value, empty items are dropped.
+func SplitFlagValues(value string) []string {
+	values := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 func AddFlags(cmd *cobra.Command, name string, value interface{}, usage string, required bool) {
 	switch v := value.(type) {
 	case string:
@@ -40,6 +53,8 @@ func AddFlags(cmd *cobra.Command, name string, value interface{}, usage string,
 		cmd.Flags().Float32(name, v, usage)
 	case bool:
 		cmd.Flags().Bool(name, v, usage)
+	case time.Duration:
+		cmd.Flags().Duration(name, v, usage)
 	default:
 		panic("Invalid flag type")
 	}
@@ -1,45 +1,140 @@
 package fxtronbridge
 
 import (
-	"fmt"
+	"context"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 
 	"github.com/functionx/fx-tron-bridge/internal/logger"
 )
 
-var BlockHeightProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: "eth_bridge_oracle", Name: "sync_block_height"})
-var BlockIntervalProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: "eth_bridge_oracle", Name: "query_log_block_interval"})
-var MsgPendingLenProm = prometheus.NewCounter(prometheus.CounterOpts{Subsystem: "eth_bridge_oracle", Name: "msg_pending_count"})
+const (
+	DefaultPrometheusListenAddr = ":9811"
+	DefaultPrometheusNamespace  = "fx"
+	// DefaultAdminListenAddr only accepts local connections, the admin api has no authentication.
+	DefaultAdminListenAddr = "127.0.0.1:9812"
 
-var FxKeyBalanceProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: "", Name: "fx_key_balance"})
-var FxUpdateOracleSetProm = prometheus.NewCounter(prometheus.CounterOpts{Subsystem: "", Name: "update_oracle_set_sign"})
-var FxSubmitBatchSignProm = prometheus.NewCounter(prometheus.CounterOpts{Subsystem: "", Name: "submit_batch_sign"})
+	// LogLevelPath is served by the admin api, GET returns the log level and PUT {"level":"debug"} changes it.
+	LogLevelPath = "/log/level"
+)
 
-func StartBridgePrometheus() {
-	prometheus.DefaultRegisterer.MustRegister(BlockHeightProm)
-	prometheus.DefaultRegisterer.MustRegister(BlockIntervalProm)
+const (
+	oracleSubsystem   = "tron_bridge_oracle"
+	singerSubsystem   = "tron_bridge_singer"
+	rpcSubsystem      = "tron_bridge_rpc"
+	solvencySubsystem = "tron_bridge_solvency"
+)
 
-	prometheus.DefaultRegisterer.MustRegister(MsgPendingLenProm)
+var BlockHeightProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "sync_block_height"})
+var BlockIntervalProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "query_log_block_interval"})
+var MsgPendingLenProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "msg_pending_count"})
+var ClaimSentProm = prometheus.NewCounterVec(prometheus.CounterOpts{Subsystem: oracleSubsystem, Name: "claim_sent_total"}, []string{"event"})
+var OracleSetCheckpointMatchProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "oracle_set_checkpoint_match"})
+var OracleSetNonceLagProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "oracle_set_nonce_lag"})
+var OracleSetPowerDiffProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "oracle_set_power_diff"})
+var OracleSetReachablePowerProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "oracle_set_reachable_power"})
+var OracleSetPowerThresholdProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: oracleSubsystem, Name: "oracle_set_power_threshold"})
 
-	prometheus.DefaultRegisterer.MustRegister(FxKeyBalanceProm)
-	prometheus.DefaultRegisterer.MustRegister(FxUpdateOracleSetProm)
-	prometheus.DefaultRegisterer.MustRegister(FxSubmitBatchSignProm)
-	go func() {
-		srv := &http.Server{
-			Addr: ":9811",
-			Handler: promhttp.InstrumentMetricHandler(
-				prometheus.DefaultRegisterer, promhttp.HandlerFor(
-					prometheus.DefaultGatherer,
-					promhttp.HandlerOpts{MaxRequestsInFlight: 3},
-				),
-			),
+var FxKeyBalanceProm = prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: singerSubsystem, Name: "fx_key_balance"})
+var FxUpdateOracleSetProm = prometheus.NewCounter(prometheus.CounterOpts{Subsystem: singerSubsystem, Name: "update_oracle_set_sign"})
+var FxSubmitBatchSignProm = prometheus.NewCounterVec(prometheus.CounterOpts{Subsystem: singerSubsystem, Name: "submit_batch_sign"}, []string{"token"})
+var BatchTimeoutBlocksProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: singerSubsystem, Name: "batch_timeout_blocks"}, []string{"token", "nonce"})
+var BatchConfirmPowerProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: singerSubsystem, Name: "batch_confirm_power"}, []string{"token", "nonce"})
+var SignWindowBlocksProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: singerSubsystem, Name: "sign_window_blocks_remaining"}, []string{"kind", "token", "nonce"})
+var SignSkippedProm = prometheus.NewCounterVec(prometheus.CounterOpts{Subsystem: singerSubsystem, Name: "sign_skipped_total"}, []string{"kind", "reason"})
+
+var TokenLockedProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: solvencySubsystem, Name: "token_locked"}, []string{"token"})
+var TokenBackedProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: solvencySubsystem, Name: "token_backed"}, []string{"token"})
+var TokenDiffRatioProm = prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: solvencySubsystem, Name: "token_diff_ratio"}, []string{"token"})
+
+var BroadcastFailProm = prometheus.NewCounterVec(prometheus.CounterOpts{Subsystem: rpcSubsystem, Name: "broadcast_fail_total"}, []string{"reason"})
+var RpcLatencyProm = prometheus.NewHistogramVec(prometheus.HistogramOpts{Subsystem: rpcSubsystem, Name: "request_duration_seconds", Buckets: prometheus.DefBuckets}, []string{"client", "method"})
+
+func bridgeCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		BlockHeightProm,
+		BlockIntervalProm,
+		MsgPendingLenProm,
+		ClaimSentProm,
+		OracleSetCheckpointMatchProm,
+		OracleSetNonceLagProm,
+		OracleSetPowerDiffProm,
+		OracleSetReachablePowerProm,
+		OracleSetPowerThresholdProm,
+
+		FxKeyBalanceProm,
+		FxUpdateOracleSetProm,
+		FxSubmitBatchSignProm,
+		SignWindowBlocksProm,
+		SignSkippedProm,
+		BatchTimeoutBlocksProm,
+		BatchConfirmPowerProm,
+
+		TokenLockedProm,
+		TokenBackedProm,
+		TokenDiffRatioProm,
+
+		BroadcastFailProm,
+		RpcLatencyProm,
+	}
+}
+
+// StartBridgePrometheus serves the bridge metrics on listenAddr, every metric name is prefixed with namespace.
+func StartBridgePrometheus(listenAddr, namespace string) error {
+	registry := prometheus.NewRegistry()
+	var registerer prometheus.Registerer = registry
+	if len(namespace) > 0 {
+		registerer = prometheus.WrapRegistererWithPrefix(namespace+"_", registry)
+	}
+	for _, collector := range bridgeCollectors() {
+		if err := registerer.Register(collector); err != nil {
+			return err
 		}
-		logger.Infof("=====> start prometheus server: http://127.0.0.1%s", srv.Addr)
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			panic(fmt.Sprintf("=====> start prometheus server failed: %v", err))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", promhttp.InstrumentMetricHandler(
+		registry, promhttp.HandlerFor(
+			registry,
+			promhttp.HandlerOpts{MaxRequestsInFlight: 3},
+		),
+	))
+	return serve("prometheus", listenAddr, mux)
+}
+
+// StartAdminServer serves the admin api on listenAddr, apart from the metrics so it can stay on a local address.
+func StartAdminServer(listenAddr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(LogLevelPath, logger.LevelHandler())
+	return serve("admin", listenAddr, mux)
+}
+
+func serve(name, listenAddr string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: handler}
+	logger.Infof("=====> start %s server: http://%s", name, listener.Addr().String())
+	go func() {
+		if err := srv.Serve(listener); err != http.ErrServerClosed {
+			logger.Errorf("=====> %s server stopped: %v", name, err)
 		}
 	}()
+	return nil
+}
+
+// RpcLatencyInterceptor records the latency of every unary call made through a grpc client.
+func RpcLatencyInterceptor(clientName string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		RpcLatencyProm.WithLabelValues(clientName, method).Observe(time.Since(start).Seconds())
+		return err
+	}
 }
@@ -3,16 +3,22 @@ package fxtronbridge
 import (
 	"bytes"
 	"net/http"
+	"strings"
 	"testing"
 )
 
 func TestStartBridgePrometheus(t *testing.T) {
-	StartBridgePrometheus()
+	if err := StartBridgePrometheus("127.0.0.1:19811", DefaultPrometheusNamespace); err != nil {
+		t.Fatal(err)
+	}
 	BlockHeightProm.Set(100)
 	BlockIntervalProm.Set(101)
-	MsgPendingLenProm.Inc()
+	MsgPendingLenProm.Set(1)
+	// the oracle labels the claims with their message type url
+	ClaimSentProm.WithLabelValues("/fx.gravity.crosschain.v1.MsgSendToFxClaim").Inc()
+	FxKeyBalanceProm.Set(12.5)
 	client := http.DefaultClient
-	get, err := client.Get("http://127.0.0.1:9811")
+	get, err := client.Get("http://127.0.0.1:19811")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -21,5 +27,47 @@ func TestStartBridgePrometheus(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	t.Log(buf.String())
+	for _, name := range []string{
+		"fx_tron_bridge_oracle_sync_block_height 100",
+		"fx_tron_bridge_oracle_claim_sent_total{event=\"/fx.gravity.crosschain.v1.MsgSendToFxClaim\"} 1",
+		"fx_tron_bridge_singer_fx_key_balance 12.5",
+	} {
+		if !strings.Contains(buf.String(), name) {
+			t.Fatalf("metric %s not found", name)
+		}
+	}
+
+	// the admin api is not served with the metrics, the path falls back to the metrics handler
+	get, err = client.Get("http://127.0.0.1:19811" + LogLevelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err = buf.ReadFrom(get.Body); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `"level"`) {
+		t.Fatal("log level served on the metrics listener")
+	}
+
+	if err = StartBridgePrometheus("127.0.0.1:19811", DefaultPrometheusNamespace); err == nil {
+		t.Fatal("expected listen error")
+	}
+}
+
+func TestStartAdminServer(t *testing.T) {
+	if err := StartAdminServer("127.0.0.1:19812"); err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:19812"+LogLevelPath, strings.NewReader(`{"level":"debug"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	put, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if put.StatusCode != http.StatusOK {
+		t.Fatalf("update log level status: %d", put.StatusCode)
+	}
 }
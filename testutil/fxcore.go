@@ -0,0 +1,368 @@
+package testutil
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	crosschaintypes "github.com/functionx/fx-core/v3/x/crosschain/types"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FxState is the chain state served by FakeFx, confirms broadcast to the fake are added to it.
+type FxState struct {
+	ChainId     string
+	BlockHeight int64
+	Balances    sdk.Coins
+	Supply      sdk.Coins
+
+	Params               crosschaintypes.Params
+	Oracle               *crosschaintypes.Oracle
+	Oracles              []crosschaintypes.Oracle
+	LastEventNonce       uint64
+	LastEventBlockHeight uint64
+	CurrentOracleSet     *crosschaintypes.OracleSet
+	OracleSets           []*crosschaintypes.OracleSet
+	PendingOracleSets    []*crosschaintypes.OracleSet
+	OracleSetConfirms    []*crosschaintypes.MsgOracleSetConfirm
+	PendingBatch         *crosschaintypes.OutgoingTxBatch
+	Batches              []*crosschaintypes.OutgoingTxBatch
+	BatchConfirms        []*crosschaintypes.MsgConfirmBatch
+	TokenDenoms          map[string]string
+}
+
+// FakeFx is an in-process fx-core node serving the crosschain query service and the cosmos
+// auth, bank, tendermint and tx services the bridge needs to query state and broadcast txs.
+type FakeFx struct {
+	URL string
+
+	mu       sync.Mutex
+	state    FxState
+	sequence uint64
+	msgs     []*codectypes.Any
+}
+
+func NewFakeFx(t testing.TB) *FakeFx {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fake := &FakeFx{
+		URL:   "http://" + listener.Addr().String(),
+		state: FxState{ChainId: "fxcore", BlockHeight: 1, TokenDenoms: make(map[string]string)},
+	}
+	server := grpc.NewServer()
+	crosschaintypes.RegisterQueryServer(server, &fxCrosschainServer{FakeFx: fake})
+	authtypes.RegisterQueryServer(server, &fxAuthServer{FakeFx: fake})
+	banktypes.RegisterQueryServer(server, &fxBankServer{FakeFx: fake})
+	tmservice.RegisterServiceServer(server, &fxTmServer{FakeFx: fake})
+	tx.RegisterServiceServer(server, &fxTxServer{FakeFx: fake})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return fake
+}
+
+func (f *FakeFx) Update(fn func(state *FxState)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(&f.state)
+}
+
+// Msgs returns every message broadcast to the fake in order.
+func (f *FakeFx) Msgs() []*codectypes.Any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*codectypes.Any{}, f.msgs...)
+}
+
+// MsgsByType returns the broadcast messages of the same type as msg.
+func (f *FakeFx) MsgsByType(msg sdk.Msg) []*codectypes.Any {
+	msgs := make([]*codectypes.Any, 0)
+	for _, any := range f.Msgs() {
+		if any.TypeUrl == sdk.MsgTypeURL(msg) {
+			msgs = append(msgs, any)
+		}
+	}
+	return msgs
+}
+
+func (f *FakeFx) broadcast(txBytes []byte) (*sdk.TxResponse, error) {
+	var txRaw tx.TxRaw
+	if err := txRaw.Unmarshal(txBytes); err != nil {
+		return nil, err
+	}
+	var txBody tx.TxBody
+	if err := txBody.Unmarshal(txRaw.BodyBytes); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, msg := range txBody.Messages {
+		switch msg.TypeUrl {
+		case sdk.MsgTypeURL(&crosschaintypes.MsgConfirmBatch{}):
+			confirm := new(crosschaintypes.MsgConfirmBatch)
+			if err := proto.Unmarshal(msg.Value, confirm); err != nil {
+				return nil, err
+			}
+			f.state.BatchConfirms = append(f.state.BatchConfirms, confirm)
+		case sdk.MsgTypeURL(&crosschaintypes.MsgOracleSetConfirm{}):
+			confirm := new(crosschaintypes.MsgOracleSetConfirm)
+			if err := proto.Unmarshal(msg.Value, confirm); err != nil {
+				return nil, err
+			}
+			f.state.OracleSetConfirms = append(f.state.OracleSetConfirms, confirm)
+		}
+	}
+	f.msgs = append(f.msgs, txBody.Messages...)
+	f.sequence++
+	f.state.BlockHeight++
+	return &sdk.TxResponse{
+		Height: f.state.BlockHeight,
+		TxHash: hex.EncodeToString(tmhash.Sum(txBytes)),
+	}, nil
+}
+
+type fxCrosschainServer struct {
+	crosschaintypes.UnimplementedQueryServer
+	*FakeFx
+}
+
+func (s *fxCrosschainServer) Params(context.Context, *crosschaintypes.QueryParamsRequest) (*crosschaintypes.QueryParamsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryParamsResponse{Params: s.state.Params}, nil
+}
+
+func (s *fxCrosschainServer) CurrentOracleSet(context.Context, *crosschaintypes.QueryCurrentOracleSetRequest) (*crosschaintypes.QueryCurrentOracleSetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryCurrentOracleSetResponse{OracleSet: s.state.CurrentOracleSet}, nil
+}
+
+func (s *fxCrosschainServer) OracleSetRequest(_ context.Context, req *crosschaintypes.QueryOracleSetRequestRequest) (*crosschaintypes.QueryOracleSetRequestResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, oracleSet := range s.state.OracleSets {
+		if oracleSet.Nonce == req.Nonce {
+			return &crosschaintypes.QueryOracleSetRequestResponse{OracleSet: oracleSet}, nil
+		}
+	}
+	return &crosschaintypes.QueryOracleSetRequestResponse{}, nil
+}
+
+func (s *fxCrosschainServer) LastOracleSetRequests(context.Context, *crosschaintypes.QueryLastOracleSetRequestsRequest) (*crosschaintypes.QueryLastOracleSetRequestsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryLastOracleSetRequestsResponse{OracleSets: s.state.OracleSets}, nil
+}
+
+func (s *fxCrosschainServer) OracleSetConfirmsByNonce(_ context.Context, req *crosschaintypes.QueryOracleSetConfirmsByNonceRequest) (*crosschaintypes.QueryOracleSetConfirmsByNonceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	confirms := make([]*crosschaintypes.MsgOracleSetConfirm, 0)
+	for _, confirm := range s.state.OracleSetConfirms {
+		if confirm.Nonce == req.Nonce {
+			confirms = append(confirms, confirm)
+		}
+	}
+	return &crosschaintypes.QueryOracleSetConfirmsByNonceResponse{Confirms: confirms}, nil
+}
+
+func (s *fxCrosschainServer) LastPendingOracleSetRequestByAddr(_ context.Context, req *crosschaintypes.QueryLastPendingOracleSetRequestByAddrRequest) (*crosschaintypes.QueryLastPendingOracleSetRequestByAddrResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := make([]*crosschaintypes.OracleSet, 0)
+	for _, oracleSet := range s.state.PendingOracleSets {
+		if !s.oracleSetConfirmed(oracleSet.Nonce, req.BridgerAddress) {
+			pending = append(pending, oracleSet)
+		}
+	}
+	return &crosschaintypes.QueryLastPendingOracleSetRequestByAddrResponse{OracleSets: pending}, nil
+}
+
+func (s *fxCrosschainServer) LastPendingBatchRequestByAddr(_ context.Context, req *crosschaintypes.QueryLastPendingBatchRequestByAddrRequest) (*crosschaintypes.QueryLastPendingBatchRequestByAddrResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := s.state.PendingBatch
+	if batch != nil && s.batchConfirmed(batch.TokenContract, batch.BatchNonce, req.BridgerAddress) {
+		batch = nil
+	}
+	return &crosschaintypes.QueryLastPendingBatchRequestByAddrResponse{Batch: batch}, nil
+}
+
+func (s *fxCrosschainServer) OutgoingTxBatches(context.Context, *crosschaintypes.QueryOutgoingTxBatchesRequest) (*crosschaintypes.QueryOutgoingTxBatchesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryOutgoingTxBatchesResponse{Batches: s.state.Batches}, nil
+}
+
+func (s *fxCrosschainServer) BatchRequestByNonce(_ context.Context, req *crosschaintypes.QueryBatchRequestByNonceRequest) (*crosschaintypes.QueryBatchRequestByNonceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, batch := range s.state.Batches {
+		if batch.TokenContract == req.TokenContract && batch.BatchNonce == req.Nonce {
+			return &crosschaintypes.QueryBatchRequestByNonceResponse{Batch: batch}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "batch not found token: %s, nonce: %d", req.TokenContract, req.Nonce)
+}
+
+func (s *fxCrosschainServer) BatchConfirms(_ context.Context, req *crosschaintypes.QueryBatchConfirmsRequest) (*crosschaintypes.QueryBatchConfirmsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	confirms := make([]*crosschaintypes.MsgConfirmBatch, 0)
+	for _, confirm := range s.state.BatchConfirms {
+		if confirm.TokenContract == req.TokenContract && confirm.Nonce == req.Nonce {
+			confirms = append(confirms, confirm)
+		}
+	}
+	return &crosschaintypes.QueryBatchConfirmsResponse{Confirms: confirms}, nil
+}
+
+func (s *fxCrosschainServer) LastEventNonceByAddr(context.Context, *crosschaintypes.QueryLastEventNonceByAddrRequest) (*crosschaintypes.QueryLastEventNonceByAddrResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryLastEventNonceByAddrResponse{EventNonce: s.state.LastEventNonce}, nil
+}
+
+func (s *fxCrosschainServer) LastEventBlockHeightByAddr(context.Context, *crosschaintypes.QueryLastEventBlockHeightByAddrRequest) (*crosschaintypes.QueryLastEventBlockHeightByAddrResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryLastEventBlockHeightByAddrResponse{BlockHeight: s.state.LastEventBlockHeight}, nil
+}
+
+func (s *fxCrosschainServer) TokenToDenom(_ context.Context, req *crosschaintypes.QueryTokenToDenomRequest) (*crosschaintypes.QueryTokenToDenomResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	denom, ok := s.state.TokenDenoms[req.Token]
+	if !ok {
+		return nil, fmt.Errorf("token %s not found", req.Token)
+	}
+	return &crosschaintypes.QueryTokenToDenomResponse{Denom: denom}, nil
+}
+
+func (s *fxCrosschainServer) GetOracleByBridgerAddr(_ context.Context, req *crosschaintypes.QueryOracleByBridgerAddrRequest) (*crosschaintypes.QueryOracleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.Oracle == nil || s.state.Oracle.BridgerAddress != req.BridgerAddress {
+		return nil, fmt.Errorf("oracle not found bridger: %s", req.BridgerAddress)
+	}
+	return &crosschaintypes.QueryOracleResponse{Oracle: s.state.Oracle}, nil
+}
+
+func (s *fxCrosschainServer) Oracles(context.Context, *crosschaintypes.QueryOraclesRequest) (*crosschaintypes.QueryOraclesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &crosschaintypes.QueryOraclesResponse{Oracles: s.state.Oracles}, nil
+}
+
+func (f *FakeFx) oracleSetConfirmed(nonce uint64, bridgerAddress string) bool {
+	for _, confirm := range f.state.OracleSetConfirms {
+		if confirm.Nonce == nonce && confirm.BridgerAddress == bridgerAddress {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FakeFx) batchConfirmed(tokenContract string, nonce uint64, bridgerAddress string) bool {
+	for _, confirm := range f.state.BatchConfirms {
+		if confirm.TokenContract == tokenContract && confirm.Nonce == nonce && confirm.BridgerAddress == bridgerAddress {
+			return true
+		}
+	}
+	return false
+}
+
+type fxAuthServer struct {
+	authtypes.UnimplementedQueryServer
+	*FakeFx
+}
+
+func (s *fxAuthServer) Account(_ context.Context, req *authtypes.QueryAccountRequest) (*authtypes.QueryAccountResponse, error) {
+	accAddress, err := sdk.AccAddressFromBech32(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, err := codectypes.NewAnyWithValue(authtypes.NewBaseAccount(accAddress, nil, 1, s.sequence))
+	if err != nil {
+		return nil, err
+	}
+	return &authtypes.QueryAccountResponse{Account: account}, nil
+}
+
+type fxBankServer struct {
+	banktypes.UnimplementedQueryServer
+	*FakeFx
+}
+
+func (s *fxBankServer) Balance(_ context.Context, req *banktypes.QueryBalanceRequest) (*banktypes.QueryBalanceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	balance := sdk.NewCoin(req.Denom, s.state.Balances.AmountOf(req.Denom))
+	return &banktypes.QueryBalanceResponse{Balance: &balance}, nil
+}
+
+func (s *fxBankServer) SupplyOf(_ context.Context, req *banktypes.QuerySupplyOfRequest) (*banktypes.QuerySupplyOfResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &banktypes.QuerySupplyOfResponse{Amount: sdk.NewCoin(req.Denom, s.state.Supply.AmountOf(req.Denom))}, nil
+}
+
+type fxTmServer struct {
+	tmservice.UnimplementedServiceServer
+	*FakeFx
+}
+
+func (s *fxTmServer) GetNodeInfo(context.Context, *tmservice.GetNodeInfoRequest) (*tmservice.GetNodeInfoResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &tmservice.GetNodeInfoResponse{
+		DefaultNodeInfo:    &tmp2p.DefaultNodeInfo{Network: s.state.ChainId},
+		ApplicationVersion: &tmservice.VersionInfo{},
+	}, nil
+}
+
+func (s *fxTmServer) GetLatestBlock(context.Context, *tmservice.GetLatestBlockRequest) (*tmservice.GetLatestBlockResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &tmservice.GetLatestBlockResponse{
+		BlockId: &tmproto.BlockID{},
+		Block:   &tmproto.Block{Header: tmproto.Header{ChainID: s.state.ChainId, Height: s.state.BlockHeight}},
+	}, nil
+}
+
+type fxTxServer struct {
+	tx.UnimplementedServiceServer
+	*FakeFx
+}
+
+func (s *fxTxServer) Simulate(context.Context, *tx.SimulateRequest) (*tx.SimulateResponse, error) {
+	return &tx.SimulateResponse{GasInfo: &sdk.GasInfo{GasWanted: 200000, GasUsed: 100000}, Result: &sdk.Result{}}, nil
+}
+
+func (s *fxTxServer) BroadcastTx(_ context.Context, req *tx.BroadcastTxRequest) (*tx.BroadcastTxResponse, error) {
+	txResponse, err := s.broadcast(req.TxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &tx.BroadcastTxResponse{TxResponse: txResponse}, nil
+}
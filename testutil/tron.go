@@ -0,0 +1,422 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/functionx/fx-tron-bridge/contract"
+)
+
+var fxBridgeAbi ethabi.ABI
+
+func init() {
+	fxBridgeLogicAbi, err := ethabi.JSON(strings.NewReader(contract.FxBridgeTronMetaData.ABI))
+	if err != nil {
+		panic("contract abi json format error")
+	}
+	fxBridgeAbi = fxBridgeLogicAbi
+}
+
+// FakeTron is an in-process tron node serving the wallet grpc methods used by the bridge,
+// blocks, event logs and constant call results are scripted by the test.
+type FakeTron struct {
+	api.UnimplementedWalletServer
+
+	URL string
+
+	mu              sync.Mutex
+	blockNumber     int64
+	blocks          map[int64][]*core.TransactionInfo
+	constants       map[string][][]byte
+	reverts         map[string][]byte
+	chainParameters map[string]int64
+	accounts        map[string]*core.Account
+	contracts       map[string]bool
+	transactions    []*core.Transaction
+}
+
+func NewFakeTron(t testing.TB) *FakeTron {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fake := &FakeTron{
+		URL:             "http://" + listener.Addr().String(),
+		blocks:          make(map[int64][]*core.TransactionInfo),
+		constants:       make(map[string][][]byte),
+		reverts:         make(map[string][]byte),
+		chainParameters: map[string]int64{"getEnergyFee": 420},
+		accounts:        make(map[string]*core.Account),
+		contracts:       make(map[string]bool),
+	}
+	server := grpc.NewServer()
+	api.RegisterWalletServer(server, fake)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return fake
+}
+
+func (f *FakeTron) SetBlockNumber(blockNumber int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blockNumber = blockNumber
+}
+
+// AddEvent appends a successful transaction to the block that emits eventName from contractAddress,
+// args are given in the order of the event inputs in the FxBridgeTron abi.
+func (f *FakeTron) AddEvent(blockNumber int64, contractAddress string, txHash []byte, eventName string, args ...interface{}) error {
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return err
+	}
+	topics, data, err := PackEventLog(eventName, args...)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contracts[string(contractAddr.Bytes())] = true
+	f.blocks[blockNumber] = append(f.blocks[blockNumber], &core.TransactionInfo{
+		Id:              txHash,
+		BlockNumber:     blockNumber,
+		ContractAddress: contractAddr.Bytes(),
+		Receipt:         &core.ResourceReceipt{Result: core.Transaction_Result_SUCCESS},
+		Log: []*core.TransactionInfo_Log{
+			{Address: contractAddr.Bytes()[1:], Topics: topics, Data: data},
+		},
+	})
+	return nil
+}
+
+// SetConstantResult scripts the result of a constant call, data is either the full call data
+// or only the method selector, a full call data match takes precedence.
+func (f *FakeTron) SetConstantResult(contractAddress string, data []byte, results ...[]byte) error {
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.constants[constantKey(contractAddr.Bytes(), data)] = results
+	f.contracts[string(contractAddr.Bytes())] = true
+	return nil
+}
+
+// SetConstantRevert makes the constant calls and transactions of a method selector revert with reason.
+func (f *FakeTron) SetConstantRevert(contractAddress string, selector []byte, reason string) error {
+	contractAddr, err := address.Base58ToAddress(contractAddress)
+	if err != nil {
+		return err
+	}
+	revertData, err := PackRevert(reason)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reverts[constantKey(contractAddr.Bytes(), selector)] = revertData
+	f.contracts[string(contractAddr.Bytes())] = true
+	return nil
+}
+
+// SetMethodResult scripts the result of a FxBridgeTron view method for any arguments,
+// outputs are packed with the method outputs in the abi.
+func (f *FakeTron) SetMethodResult(contractAddress, method string, outputs ...interface{}) error {
+	abiMethod, ok := fxBridgeAbi.Methods[method]
+	if !ok {
+		return fmt.Errorf("method %s not found", method)
+	}
+	result, err := abiMethod.Outputs.Pack(outputs...)
+	if err != nil {
+		return err
+	}
+	return f.SetConstantResult(contractAddress, abiMethod.ID, result)
+}
+
+func (f *FakeTron) SetChainParameter(key string, value int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.chainParameters[key] = value
+}
+
+// SetAccount replaces the account returned by GetAccount, accounts that are not set have the default
+// owner permission of their own key with threshold 1.
+func (f *FakeTron) SetAccount(account *core.Account) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accounts[string(account.Address)] = account
+}
+
+func (f *FakeTron) GetAccount(_ context.Context, in *core.Account) (*core.Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if account, ok := f.accounts[string(in.Address)]; ok {
+		return account, nil
+	}
+	return &core.Account{
+		Address: in.Address,
+		OwnerPermission: &core.Permission{
+			Type:           core.Permission_Owner,
+			PermissionName: "owner",
+			Threshold:      1,
+			Keys:           []*core.Key{{Address: in.Address, Weight: 1}},
+		},
+	}, nil
+}
+
+func (f *FakeTron) Transactions() []*core.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*core.Transaction{}, f.transactions...)
+}
+
+func (f *FakeTron) GetNowBlock2(context.Context, *api.EmptyMessage) (*api.BlockExtention, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &api.BlockExtention{
+		BlockHeader: &core.BlockHeader{RawData: &core.BlockHeaderRaw{Number: f.blockNumber}},
+	}, nil
+}
+
+func (f *FakeTron) GetTransactionInfoByBlockNum(_ context.Context, in *api.NumberMessage) (*api.TransactionInfoList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if in.Num > f.blockNumber {
+		return nil, fmt.Errorf("block %d not found", in.Num)
+	}
+	return &api.TransactionInfoList{TransactionInfo: f.blocks[in.Num]}, nil
+}
+
+func (f *FakeTron) TriggerConstantContract(_ context.Context, in *troncontract.TriggerSmartContract) (*api.TransactionExtention, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(in.Data) >= 4 {
+		if revertData, ok := f.reverts[constantKey(in.ContractAddress, in.Data[:4])]; ok {
+			return &api.TransactionExtention{
+				Transaction: &core.Transaction{
+					Ret: []*core.Transaction_Result{{Ret: core.Transaction_Result_FAILED, ContractRet: core.Transaction_Result_REVERT}},
+				},
+				ConstantResult: [][]byte{revertData},
+				Result:         &api.Return{Result: true, Code: api.Return_SUCCESS},
+			}, nil
+		}
+	}
+	results, ok := f.constants[constantKey(in.ContractAddress, in.Data)]
+	if !ok && len(in.Data) >= 4 {
+		results, ok = f.constants[constantKey(in.ContractAddress, in.Data[:4])]
+	}
+	if !ok {
+		return nil, fmt.Errorf("constant call not scripted contract: %s, data: %s", hex.EncodeToString(in.ContractAddress), hex.EncodeToString(in.Data))
+	}
+	return &api.TransactionExtention{
+		Transaction: &core.Transaction{
+			Ret: []*core.Transaction_Result{{Ret: core.Transaction_Result_SUCESS}},
+		},
+		ConstantResult: results,
+		Result:         &api.Return{Result: true, Code: api.Return_SUCCESS},
+	}, nil
+}
+
+func (f *FakeTron) GetChainParameters(context.Context, *api.EmptyMessage) (*core.ChainParameters, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parameters := make([]*core.ChainParameters_ChainParameter, 0, len(f.chainParameters))
+	for key, value := range f.chainParameters {
+		parameters = append(parameters, &core.ChainParameters_ChainParameter{Key: key, Value: value})
+	}
+	return &core.ChainParameters{ChainParameter: parameters}, nil
+}
+
+// TriggerContract builds an unsigned transaction calling the contract.
+func (f *FakeTron) TriggerContract(_ context.Context, in *troncontract.TriggerSmartContract) (*api.TransactionExtention, error) {
+	parameter, err := anypb.New(in)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Contract: []*core.Transaction_Contract{
+			{Type: core.Transaction_Contract_TriggerSmartContract, Parameter: parameter},
+		},
+		RefBlockNum: f.blockNumber,
+		Expiration:  time.Now().Add(time.Minute).UnixMilli(),
+	}}
+	txId, err := transactionId(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.TransactionExtention{Transaction: tx, Txid: txId, Result: &api.Return{Result: true, Code: api.Return_SUCCESS}}, nil
+}
+
+// GetContract returns a contract with code for the deployed contracts and the contracts with scripted calls.
+func (f *FakeTron) GetContract(_ context.Context, in *api.BytesMessage) (*troncontract.SmartContract, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.contracts[string(in.Value)] {
+		return &troncontract.SmartContract{}, nil
+	}
+	return &troncontract.SmartContract{ContractAddress: in.Value, Bytecode: []byte{0x60, 0x80, 0x60, 0x40}}, nil
+}
+
+// DeployContract builds an unsigned transaction creating the contract.
+func (f *FakeTron) DeployContract(_ context.Context, in *troncontract.CreateSmartContract) (*api.TransactionExtention, error) {
+	parameter, err := anypb.New(in)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Contract: []*core.Transaction_Contract{
+			{Type: core.Transaction_Contract_CreateSmartContract, Parameter: parameter},
+		},
+		RefBlockNum: f.blockNumber,
+		Expiration:  time.Now().Add(time.Minute).UnixMilli(),
+	}}
+	txId, err := transactionId(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.TransactionExtention{Transaction: tx, Txid: txId, Result: &api.Return{Result: true, Code: api.Return_SUCCESS}}, nil
+}
+
+// ContractAddress is the address tron gives to the contract created by the transaction txId of owner.
+func ContractAddress(txId, owner []byte) address.Address {
+	hash := crypto.Keccak256(append(append([]byte{}, txId...), owner...))
+	return append([]byte{address.TronBytePrefix}, hash[len(hash)-20:]...)
+}
+
+// GetTransactionInfoById returns a successful receipt for a broadcast transaction, and an empty info otherwise,
+// a transaction calling a reverting method fails with the revert reason.
+func (f *FakeTron) GetTransactionInfoById(_ context.Context, in *api.BytesMessage) (*core.TransactionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, tx := range f.transactions {
+		txId, err := transactionId(tx)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(txId, in.Value) {
+			continue
+		}
+		info := &core.TransactionInfo{Id: txId, BlockNumber: f.blockNumber, Receipt: &core.ResourceReceipt{Result: core.Transaction_Result_SUCCESS}}
+		if tx.RawData.Contract[0].Type == core.Transaction_Contract_CreateSmartContract {
+			create := new(troncontract.CreateSmartContract)
+			if err = tx.RawData.Contract[0].Parameter.UnmarshalTo(create); err != nil {
+				return nil, err
+			}
+			info.ContractAddress = ContractAddress(txId, create.OwnerAddress).Bytes()
+			return info, nil
+		}
+		trigger := new(troncontract.TriggerSmartContract)
+		if err = tx.RawData.Contract[0].Parameter.UnmarshalTo(trigger); err != nil {
+			return nil, err
+		}
+		if len(trigger.Data) >= 4 {
+			if revertData, ok := f.reverts[constantKey(trigger.ContractAddress, trigger.Data[:4])]; ok {
+				info.Receipt.Result = core.Transaction_Result_REVERT
+				info.ContractResult = [][]byte{revertData}
+			}
+		}
+		return info, nil
+	}
+	return &core.TransactionInfo{}, nil
+}
+
+func (f *FakeTron) BroadcastTransaction(_ context.Context, in *core.Transaction) (*api.Return, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.transactions = append(f.transactions, in)
+	if in.RawData.Contract[0].Type == core.Transaction_Contract_CreateSmartContract {
+		create := new(troncontract.CreateSmartContract)
+		if err := in.RawData.Contract[0].Parameter.UnmarshalTo(create); err != nil {
+			return nil, err
+		}
+		txId, err := transactionId(in)
+		if err != nil {
+			return nil, err
+		}
+		f.contracts[string(ContractAddress(txId, create.OwnerAddress))] = true
+	}
+	return &api.Return{Result: true, Code: api.Return_SUCCESS}, nil
+}
+
+// PackEventLog builds the topics and data of a FxBridgeTron event log.
+func PackEventLog(eventName string, args ...interface{}) ([][]byte, []byte, error) {
+	event, ok := fxBridgeAbi.Events[eventName]
+	if !ok {
+		return nil, nil, fmt.Errorf("event %s not found", eventName)
+	}
+	if len(args) != len(event.Inputs) {
+		return nil, nil, fmt.Errorf("event %s expect %d args, got %d", eventName, len(event.Inputs), len(args))
+	}
+	topics := [][]byte{event.ID.Bytes()}
+	nonIndexed := make([]interface{}, 0, len(args))
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, args[i])
+			continue
+		}
+		hashes, err := ethabi.MakeTopics([]interface{}{args[i]})
+		if err != nil {
+			return nil, nil, err
+		}
+		topics = append(topics, hashes[0][0].Bytes())
+	}
+	data, err := event.Inputs.NonIndexed().Pack(nonIndexed...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return topics, data, nil
+}
+
+// PackRevert builds the Error(string) revert data of a solidity require.
+func PackRevert(reason string) ([]byte, error) {
+	stringType, err := ethabi.NewType("string", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ethabi.Arguments{{Type: stringType}}.Pack(reason)
+	if err != nil {
+		return nil, err
+	}
+	return append(Selector("Error(string)"), data...), nil
+}
+
+func transactionId(tx *core.Transaction) ([]byte, error) {
+	rawData, err := proto.Marshal(tx.GetRawData())
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(rawData)
+	return hash[:], nil
+}
+
+// Selector returns the 4 bytes method id of a solidity method signature, e.g. "state_lastOracleSetNonce()".
+func Selector(method string) []byte {
+	return crypto.Keccak256([]byte(method))[:4]
+}
+
+func constantKey(contractAddress, data []byte) string {
+	return hex.EncodeToString(contractAddress) + "/" + hex.EncodeToString(data)
+}
@@ -0,0 +1,259 @@
+package testutil
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	troncontract "github.com/fbsobreira/gotron-sdk/pkg/proto/core/contract"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpRequest holds the fields of the wallet http requests, addresses are base58 as sent with visible true.
+type httpRequest struct {
+	Num             int64  `json:"num"`
+	Value           string `json:"value"`
+	Address         string `json:"address"`
+	OwnerAddress    string `json:"owner_address"`
+	ContractAddress string `json:"contract_address"`
+	Data            string `json:"data"`
+	FeeLimit        int64  `json:"fee_limit"`
+	Transaction     string `json:"transaction"`
+}
+
+// ServeHttp serves the wallet http api of java-tron from the same state as the grpc methods and returns its url.
+func (f *FakeTron) ServeHttp(t testing.TB) string {
+	mux := http.NewServeMux()
+	handle := func(path string, fn func(request httpRequest) (interface{}, error)) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			var request httpRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			response, err := fn(request)
+			if err != nil {
+				response = map[string]string{"Error": err.Error()}
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		})
+	}
+	handle("/wallet/getnowblock", func(httpRequest) (interface{}, error) {
+		block, err := f.GetNowBlock2(context.Background(), &api.EmptyMessage{})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"block_header": map[string]interface{}{"raw_data": map[string]int64{"number": block.BlockHeader.RawData.Number}}}, nil
+	})
+	handle("/wallet/gettransactioninfobyblocknum", func(request httpRequest) (interface{}, error) {
+		infos, err := f.GetTransactionInfoByBlockNum(context.Background(), &api.NumberMessage{Num: request.Num})
+		if err != nil {
+			return nil, err
+		}
+		if len(infos.TransactionInfo) <= 0 {
+			return struct{}{}, nil
+		}
+		response := make([]interface{}, 0, len(infos.TransactionInfo))
+		for _, info := range infos.TransactionInfo {
+			response = append(response, httpTransactionInfo(info))
+		}
+		return response, nil
+	})
+	handle("/wallet/gettransactioninfobyid", func(request httpRequest) (interface{}, error) {
+		txId, err := hex.DecodeString(request.Value)
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.GetTransactionInfoById(context.Background(), &api.BytesMessage{Value: txId})
+		if err != nil {
+			return nil, err
+		}
+		if len(info.Id) <= 0 {
+			return struct{}{}, nil
+		}
+		return httpTransactionInfo(info), nil
+	})
+	handle("/wallet/triggerconstantcontract", func(request httpRequest) (interface{}, error) {
+		trigger, err := request.triggerSmartContract()
+		if err != nil {
+			return nil, err
+		}
+		tx, err := f.TriggerConstantContract(context.Background(), trigger)
+		if err != nil {
+			return map[string]interface{}{"result": map[string]string{"code": "CONTRACT_VALIDATE_ERROR", "message": hex.EncodeToString([]byte(err.Error()))}}, nil
+		}
+		constantResult := make([]string, 0, len(tx.ConstantResult))
+		for _, result := range tx.ConstantResult {
+			constantResult = append(constantResult, hex.EncodeToString(result))
+		}
+		rets := make([]map[string]string, 0, len(tx.Transaction.Ret))
+		for _, ret := range tx.Transaction.Ret {
+			// the node omits the default values
+			jsonRet := make(map[string]string)
+			if ret.Ret != core.Transaction_Result_SUCESS {
+				jsonRet["ret"] = ret.Ret.String()
+			}
+			if ret.ContractRet != core.Transaction_Result_DEFAULT {
+				jsonRet["contractRet"] = ret.ContractRet.String()
+			}
+			rets = append(rets, jsonRet)
+		}
+		return map[string]interface{}{
+			"result":          map[string]bool{"result": true},
+			"energy_used":     tx.EnergyUsed,
+			"constant_result": constantResult,
+			"transaction":     map[string]interface{}{"ret": rets},
+		}, nil
+	})
+	handle("/wallet/triggersmartcontract", func(request httpRequest) (interface{}, error) {
+		trigger, err := request.triggerSmartContract()
+		if err != nil {
+			return nil, err
+		}
+		tx, err := f.TriggerContract(context.Background(), trigger)
+		if err != nil {
+			return nil, err
+		}
+		tx.Transaction.RawData.FeeLimit = request.FeeLimit
+		rawData, err := proto.Marshal(tx.Transaction.RawData)
+		if err != nil {
+			return nil, err
+		}
+		txId, err := transactionId(tx.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"result":      map[string]bool{"result": true},
+			"transaction": map[string]interface{}{"visible": true, "txID": hex.EncodeToString(txId), "raw_data_hex": hex.EncodeToString(rawData)},
+		}, nil
+	})
+	handle("/wallet/broadcasthex", func(request httpRequest) (interface{}, error) {
+		txBytes, err := hex.DecodeString(request.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		tx := new(core.Transaction)
+		if err = proto.Unmarshal(txBytes, tx); err != nil {
+			return nil, err
+		}
+		if _, err = f.BroadcastTransaction(context.Background(), tx); err != nil {
+			return nil, err
+		}
+		txId, err := transactionId(tx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"result": true, "txid": hex.EncodeToString(txId)}, nil
+	})
+	handle("/wallet/getchainparameters", func(httpRequest) (interface{}, error) {
+		parameters, err := f.GetChainParameters(context.Background(), &api.EmptyMessage{})
+		if err != nil {
+			return nil, err
+		}
+		chainParameters := make([]interface{}, 0, len(parameters.ChainParameter))
+		for _, parameter := range parameters.ChainParameter {
+			chainParameters = append(chainParameters, map[string]interface{}{"key": parameter.Key, "value": parameter.Value})
+		}
+		return map[string]interface{}{"chainParameter": chainParameters}, nil
+	})
+	handle("/wallet/getaccount", func(request httpRequest) (interface{}, error) {
+		accountAddr, err := address.Base58ToAddress(request.Address)
+		if err != nil {
+			return nil, err
+		}
+		account, err := f.GetAccount(context.Background(), &core.Account{Address: accountAddr.Bytes()})
+		if err != nil {
+			return nil, err
+		}
+		response := map[string]interface{}{"address": request.Address}
+		if account.OwnerPermission != nil {
+			response["owner_permission"] = httpPermission(account.OwnerPermission)
+		}
+		activePermissions := make([]interface{}, 0, len(account.ActivePermission))
+		for _, permission := range account.ActivePermission {
+			activePermissions = append(activePermissions, httpPermission(permission))
+		}
+		response["active_permission"] = activePermissions
+		return response, nil
+	})
+	handle("/wallet/getcontract", func(request httpRequest) (interface{}, error) {
+		contractAddr, err := address.Base58ToAddress(request.Value)
+		if err != nil {
+			return nil, err
+		}
+		smartContract, err := f.GetContract(context.Background(), &api.BytesMessage{Value: contractAddr.Bytes()})
+		if err != nil {
+			return nil, err
+		}
+		if len(smartContract.Bytecode) <= 0 {
+			return struct{}{}, nil
+		}
+		return map[string]string{"contract_address": request.Value, "bytecode": hex.EncodeToString(smartContract.Bytecode)}, nil
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func (r httpRequest) triggerSmartContract() (*troncontract.TriggerSmartContract, error) {
+	ownerAddr, err := address.Base58ToAddress(r.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	contractAddr, err := address.Base58ToAddress(r.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(r.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &troncontract.TriggerSmartContract{OwnerAddress: ownerAddr.Bytes(), ContractAddress: contractAddr.Bytes(), Data: data}, nil
+}
+
+// httpTransactionInfo prints a transaction info as the node does without visible, bytes are hex.
+func httpTransactionInfo(info *core.TransactionInfo) map[string]interface{} {
+	contractResult := make([]string, 0, len(info.ContractResult))
+	for _, result := range info.ContractResult {
+		contractResult = append(contractResult, hex.EncodeToString(result))
+	}
+	logs := make([]interface{}, 0, len(info.Log))
+	for _, log := range info.Log {
+		topics := make([]string, 0, len(log.Topics))
+		for _, topic := range log.Topics {
+			topics = append(topics, hex.EncodeToString(topic))
+		}
+		logs = append(logs, map[string]interface{}{"address": hex.EncodeToString(log.Address), "topics": topics, "data": hex.EncodeToString(log.Data)})
+	}
+	return map[string]interface{}{
+		"id":               hex.EncodeToString(info.Id),
+		"fee":              info.Fee,
+		"blockNumber":      info.BlockNumber,
+		"contractResult":   contractResult,
+		"contract_address": hex.EncodeToString(info.ContractAddress),
+		"receipt":          map[string]interface{}{"energy_usage_total": info.GetReceipt().GetEnergyUsageTotal(), "result": info.GetReceipt().GetResult().String()},
+		"log":              logs,
+	}
+}
+
+func httpPermission(permission *core.Permission) map[string]interface{} {
+	keys := make([]interface{}, 0, len(permission.Keys))
+	for _, key := range permission.Keys {
+		keys = append(keys, map[string]interface{}{"address": address.Address(key.Address).String(), "weight": key.Weight})
+	}
+	return map[string]interface{}{
+		"id":              permission.Id,
+		"permission_name": permission.PermissionName,
+		"threshold":       permission.Threshold,
+		"operations":      hex.EncodeToString(permission.Operations),
+		"keys":            keys,
+	}
+}